@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/breaking"
+	"github.com/pbufio/pbuf-cli/internal/model"
+	"github.com/pbufio/pbuf-cli/internal/registry"
+	"github.com/pbufio/pbuf-cli/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+// NewBreakingCmd creates cobra command for breaking
+func NewBreakingCmd(config *model.Config, client v1.RegistryClient) *cobra.Command {
+	breakingCmd := &cobra.Command{
+		Use:   "breaking --against [tag]",
+		Short: "Breaking",
+		Long:  "Breaking is a command to detect breaking changes between the exported proto files and the module tag",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if config.Name == "" {
+				return fmt.Errorf("module name is required. see pbuf.yaml reference")
+			}
+
+			against, err := cmd.Flags().GetString("against")
+			if err != nil {
+				return err
+			}
+
+			rules, err := cmd.Flags().GetString("rules")
+			if err != nil {
+				return err
+			}
+
+			ruleSet, err := breaking.ParseRuleSet(rules)
+			if err != nil {
+				return err
+			}
+
+			protoFiles, err := registry.CollectExportedProtoFiles(config.Export)
+			if err != nil {
+				return fmt.Errorf("failed to collect proto files: %w", err)
+			}
+
+			changes, err := findBreakingChanges(cmd.Context(), config, client, protoFiles, against, ruleSet)
+			if err != nil {
+				return err
+			}
+
+			lines := make([]string, 0, len(changes))
+			for _, change := range changes {
+				lines = append(lines, change.String())
+			}
+
+			if changes == nil {
+				changes = []*breaking.Change{}
+			}
+
+			err = renderFindings(cmd, changes, lines)
+			if err != nil {
+				return err
+			}
+
+			if len(changes) == 0 {
+				log.Printf("no breaking changes found against %s (rules: %s)", against, ruleSet)
+				return nil
+			}
+
+			return fmt.Errorf("%d breaking changes found against %s", len(changes), against)
+		},
+	}
+
+	breakingCmd.Flags().String("against", "", "module tag to compare with")
+	breakingCmd.Flags().String("rules", string(breaking.RuleSetSource), "rule set: wire|source")
+	_ = breakingCmd.MarkFlagRequired("against")
+
+	return breakingCmd
+}
+
+// findBreakingChanges compares the local proto files with the files of the module tag
+func findBreakingChanges(
+	ctx context.Context,
+	config *model.Config,
+	client v1.RegistryClient,
+	localFiles []*v1.ProtoFile,
+	tag string,
+	ruleSet breaking.RuleSet,
+) ([]*breaking.Change, error) {
+	pulled, err := client.PullModule(ctx, &v1.PullModuleRequest{
+		Name: config.Name,
+		Tag:  tag,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull module %s@%s: %w", config.Name, tag, err)
+	}
+
+	current, err := schema.ParseAll(localFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := schema.ParseAll(pulled.Protofiles)
+	if err != nil {
+		return nil, err
+	}
+
+	return breaking.Compare(previous, current, ruleSet), nil
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/browse"
+	"github.com/pbufio/pbuf-cli/internal/model"
+	"github.com/spf13/cobra"
+)
+
+// NewBrowseCmd creates cobra command for browse
+func NewBrowseCmd(config *model.Config, registryClient v1.RegistryClient, metadataClient v1.MetadataServiceClient) *cobra.Command {
+	browseCmd := &cobra.Command{
+		Use:   "browse",
+		Short: "Browse",
+		Long: "Browse is a command to navigate registry modules, tags, dependencies, files and metadata " +
+			"in an interactive terminal UI",
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			add := func(module, tag string) error {
+				if tag == "" {
+					return fmt.Errorf("no tag to add %s with", module)
+				}
+				config.AddModule(module, tag)
+				return config.Save()
+			}
+
+			browser := browse.New(cmd.Context(), registryClient, metadataClient, add)
+			_, err := tea.NewProgram(browser, tea.WithAltScreen(), tea.WithContext(cmd.Context())).Run()
+			return err
+		},
+	}
+
+	return browseCmd
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// confirm asks the question on stderr, so it does not mix with the rendered data,
+// and returns an error unless it is answered yes on stdin
+func confirm(cmd *cobra.Command, question, aborted string) error {
+	_, err := fmt.Fprint(cmd.ErrOrStderr(), question+" [y/N]: ")
+	if err != nil {
+		return err
+	}
+
+	answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return fmt.Errorf("%s. use --yes to skip the confirmation", aborted)
+	}
+}
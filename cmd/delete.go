@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+// confirmDeletion shows the tags that will be deleted with their file counts
+// and the module tags depending on them, then asks for confirmation unless --yes is set
+func confirmDeletion(cmd *cobra.Command, client v1.RegistryClient, name string, tags []string, dependencyTag string) error {
+	yes, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		return err
+	}
+
+	log.Printf("the following tags of module %s will be deleted:", name)
+	for _, tag := range tags {
+		pulled, err := client.PullModule(cmd.Context(), &v1.PullModuleRequest{
+			Name: name,
+			Tag:  tag,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to pull module %s@%s: %w", name, tag, err)
+		}
+		log.Printf("  %s (%d files)", tag, len(pulled.Protofiles))
+	}
+
+	dependents, err := registry.FindDependents(cmd.Context(), client, name, dependencyTag)
+	if err != nil {
+		return fmt.Errorf("failed to find dependents: %w", err)
+	}
+
+	if len(dependents) > 0 {
+		log.Printf("the following module tags depend on it and will fail to vendor:")
+		for _, dependent := range dependents {
+			log.Printf("  %s", dependent)
+		}
+	}
+
+	if yes {
+		return nil
+	}
+
+	return confirm(cmd, "delete?", "deletion aborted")
+}
@@ -1,16 +1,31 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"text/tabwriter"
+	"time"
 
+	"github.com/jdx/go-netrc"
 	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/drift"
 	"github.com/pbufio/pbuf-cli/internal/model"
+	"github.com/pbufio/pbuf-cli/internal/modules"
+	"github.com/pbufio/pbuf-cli/internal/output"
+	"github.com/pbufio/pbuf-cli/internal/registry"
 	"github.com/spf13/cobra"
 )
 
-func NewDriftCmd(_ *model.Config, client v1.DriftServiceClient) *cobra.Command {
+func NewDriftCmd(config *model.Config, netrcAuth *netrc.Netrc, client v1.DriftServiceClient, registryClient v1.RegistryClient) *cobra.Command {
 	driftCmd := &cobra.Command{
 		Use:   "drift",
 		Short: "Drift",
@@ -22,17 +37,25 @@ func NewDriftCmd(_ *model.Config, client v1.DriftServiceClient) *cobra.Command {
 
 	driftCmd.AddCommand(newListDriftEventsCmd(client))
 	driftCmd.AddCommand(newGetModuleDriftEventsCmd(client))
-	driftCmd.AddCommand(newGetModuleDependencyDriftStatusCmd(client))
+	driftCmd.AddCommand(newShowDriftEventCmd(client, registryClient))
+	driftCmd.AddCommand(newGetModuleDependencyDriftStatusCmd(config, client))
+	driftCmd.AddCommand(newFixDependencyDriftCmd(config, netrcAuth, client, registryClient))
+	driftCmd.AddCommand(newAckDriftEventsCmd(client))
+	driftCmd.AddCommand(newWatchDriftEventsCmd(client))
+	driftCmd.AddCommand(newCheckDriftEventsCmd(config, client))
+	driftCmd.AddCommand(newLocalDriftCmd(config, client, registryClient))
+	driftCmd.AddCommand(newNotifyDriftEventsCmd(config, client))
 
 	return driftCmd
 }
 
 func newListDriftEventsCmd(client v1.DriftServiceClient) *cobra.Command {
 	listCmd := &cobra.Command{
-		Use:   "list",
-		Short: "List drift events",
-		Long:  "List is a command to list all drift events",
-		Args:  cobra.ExactArgs(0),
+		Use:         "list",
+		Short:       "List drift events",
+		Long:        "List is a command to list all drift events",
+		Args:        cobra.ExactArgs(0),
+		Annotations: jsonByDefault(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			unacknowledgedOnly, err := cmd.Flags().GetBool("unacknowledged-only")
 			if err != nil {
@@ -46,7 +69,7 @@ func newListDriftEventsCmd(client v1.DriftServiceClient) *cobra.Command {
 				return err
 			}
 
-			return printJSON(resp)
+			return renderDriftEvents(cmd, resp, resp.Events)
 		},
 	}
 
@@ -56,10 +79,11 @@ func newListDriftEventsCmd(client v1.DriftServiceClient) *cobra.Command {
 
 func newGetModuleDriftEventsCmd(client v1.DriftServiceClient) *cobra.Command {
 	getCmd := &cobra.Command{
-		Use:   "module [module_name]",
-		Short: "Get module drift events",
-		Long:  "Module is a command to get drift events for a specific module",
-		Args:  cobra.ExactArgs(1),
+		Use:         "module [module_name]",
+		Short:       "Get module drift events",
+		Long:        "Module is a command to get drift events for a specific module",
+		Args:        cobra.ExactArgs(1),
+		Annotations: jsonByDefault(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			moduleName := args[0]
 			tagName, err := cmd.Flags().GetString("tag")
@@ -79,7 +103,7 @@ func newGetModuleDriftEventsCmd(client v1.DriftServiceClient) *cobra.Command {
 				return err
 			}
 
-			return printJSON(resp)
+			return renderDriftEvents(cmd, resp, resp.Events)
 		},
 	}
 
@@ -87,19 +111,199 @@ func newGetModuleDriftEventsCmd(client v1.DriftServiceClient) *cobra.Command {
 	return getCmd
 }
 
-func newGetModuleDependencyDriftStatusCmd(client v1.DriftServiceClient) *cobra.Command {
+func newShowDriftEventCmd(client v1.DriftServiceClient, registryClient v1.RegistryClient) *cobra.Command {
+	showCmd := &cobra.Command{
+		Use:   "show [event_id]",
+		Short: "Show drift event",
+		Long: "Show is a command to show how the file of a drift event changed: a unified diff of the previous " +
+			"content, found in the local snapshots or the vendored files, and the current one with the breaking changes",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			eventID := args[0]
+
+			moduleName, err := cmd.Flags().GetString("module")
+			if err != nil {
+				return err
+			}
+
+			event, err := findDriftEvent(cmd.Context(), client, eventID, moduleName)
+			if err != nil {
+				return err
+			}
+
+			renderer, err := newRenderer(cmd)
+			if err != nil {
+				return err
+			}
+
+			current, err := currentContent(cmd.Context(), registryClient, event)
+			if err != nil {
+				return err
+			}
+
+			var previous []byte
+			var source string
+			if event.GetEventType() != v1.DriftEventType_DRIFT_EVENT_TYPE_ADDED {
+				lock, err := registry.LoadLock(registry.LockFilename)
+				if err != nil {
+					return err
+				}
+
+				// the vendored files can still be used without the snapshots
+				snapshotDir, snapshotErr := registry.SnapshotDir()
+
+				var ok bool
+				previous, source, ok, err = drift.PreviousContent(event, lock, snapshotDir)
+				if err != nil {
+					return err
+				}
+				if !ok && snapshotErr != nil {
+					return fmt.Errorf("previous content of %s with hash %s is not in the vendored files "+
+						"and the snapshots were skipped: %w", event.GetFilename(), event.GetPreviousHash(), snapshotErr)
+				}
+				if !ok {
+					return fmt.Errorf("previous content of %s with hash %s is not available locally, "+
+						"vendor the module before the drift to keep it", event.GetFilename(), event.GetPreviousHash())
+				}
+				if snapshotErr != nil {
+					source = fmt.Sprintf("%s (snapshots skipped: %v)", source, snapshotErr)
+				}
+			}
+
+			eventDiff, err := drift.NewEventDiff(event, source, previous, current)
+			if err != nil {
+				return err
+			}
+
+			if !renderer.IsTable() {
+				return renderer.Render(eventDiff.Value(), nil)
+			}
+
+			return printEventDiff(cmd.OutOrStdout(), eventDiff)
+		},
+	}
+
+	showCmd.Flags().String("module", "", "module of the event, to look it up among the module events only")
+	return showCmd
+}
+
+// findDriftEvent looks the event up among the events of the module,
+// or among all events, including the acknowledged ones, if the module is empty
+func findDriftEvent(ctx context.Context, client v1.DriftServiceClient, eventID, moduleName string) (*v1.DriftEvent, error) {
+	var events []*v1.DriftEvent
+	if moduleName != "" {
+		resp, err := client.GetModuleDriftEvents(ctx, &v1.GetModuleDriftEventsRequest{
+			ModuleName: moduleName,
+		})
+		if err != nil {
+			return nil, err
+		}
+		events = resp.GetEvents()
+	} else {
+		resp, err := client.ListDriftEvents(ctx, &v1.ListDriftEventsRequest{
+			UnacknowledgedOnly: false,
+		})
+		if err != nil {
+			return nil, err
+		}
+		events = resp.GetEvents()
+	}
+
+	for _, event := range events {
+		if event.GetId() == eventID {
+			return event, nil
+		}
+	}
+
+	return nil, fmt.Errorf("drift event %s not found", eventID)
+}
+
+// currentContent pulls the file of the event at the module tag. It returns nil if the file is deleted
+func currentContent(ctx context.Context, registryClient v1.RegistryClient, event *v1.DriftEvent) ([]byte, error) {
+	if event.GetEventType() == v1.DriftEventType_DRIFT_EVENT_TYPE_DELETED {
+		return nil, nil
+	}
+
+	pulled, err := registryClient.PullModule(ctx, &v1.PullModuleRequest{
+		Name: event.GetModuleName(),
+		Tag:  event.GetTagName(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull %s@%s: %w", event.GetModuleName(), event.GetTagName(), err)
+	}
+
+	for _, protoFile := range pulled.GetProtofiles() {
+		if protoFile.GetFilename() != event.GetFilename() {
+			continue
+		}
+
+		content := []byte(protoFile.GetContent())
+		if event.GetCurrentHash() != "" && registry.FileHash(content) != event.GetCurrentHash() {
+			log.Printf("%s changed again after the drift event, showing the latest content", event.GetFilename())
+		}
+		return content, nil
+	}
+
+	return nil, fmt.Errorf("%s not found in %s@%s", event.GetFilename(), event.GetModuleName(), event.GetTagName())
+}
+
+func printEventDiff(w io.Writer, eventDiff *drift.EventDiff) error {
+	event := eventDiff.Event
+	if _, err := fmt.Fprintf(w, "Drift event %s: %s %s in %s@%s (%s)\n",
+		event.GetId(), event.GetFilename(),
+		strings.ToLower(strings.TrimPrefix(event.GetEventType().String(), "DRIFT_EVENT_TYPE_")),
+		event.GetModuleName(), event.GetTagName(), drift.SeverityName(event.GetSeverity())); err != nil {
+		return err
+	}
+	if eventDiff.PreviousSource != "" {
+		if _, err := fmt.Fprintf(w, "Previous content: %s\n", eventDiff.PreviousSource); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "\n%s", eventDiff.Diff); err != nil {
+		return err
+	}
+
+	if len(eventDiff.Changes) == 0 {
+		_, err := fmt.Fprintln(w, "\nNo breaking changes.")
+		return err
+	}
+
+	if _, err := fmt.Fprintln(w, "\nBreaking changes:"); err != nil {
+		return err
+	}
+	for _, change := range eventDiff.Changes {
+		if _, err := fmt.Fprintf(w, "  %s\n", change); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newGetModuleDependencyDriftStatusCmd(config *model.Config, client v1.DriftServiceClient) *cobra.Command {
 	getCmd := &cobra.Command{
 		Use:   "dependencies [module_name]",
 		Short: "Get dependency drift status",
-		Long:  "Dependencies is a command to get dependency drift status for a specific module",
-		Args:  cobra.ExactArgs(1),
+		Long: "Dependencies is a command to get dependency drift status for a specific module, " +
+			"or for all registry modules of pbuf.yaml at their pinned tags when the module is omitted",
+		Args: cobra.RangeArgs(0, 1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			moduleName := args[0]
 			tagName, err := cmd.Flags().GetString("tag")
 			if err != nil {
 				return err
 			}
 
+			if len(args) == 0 {
+				if tagName != "" {
+					return fmt.Errorf("--tag requires a module name")
+				}
+				return projectDependencyDriftStatus(cmd, config, client)
+			}
+
+			moduleName := args[0]
+
 			req := &v1.GetModuleDependencyDriftStatusRequest{
 				ModuleName: moduleName,
 			}
@@ -112,7 +316,20 @@ func newGetModuleDependencyDriftStatusCmd(client v1.DriftServiceClient) *cobra.C
 				return err
 			}
 
-			return printDependencyDriftStatuses(cmd.OutOrStdout(), moduleName, tagName, resp.GetStatuses())
+			renderer, err := newRenderer(cmd)
+			if err != nil {
+				return err
+			}
+
+			if renderer.Format() == output.FormatTable {
+				return printDependencyDriftStatuses(cmd.OutOrStdout(), moduleName, tagName, resp.GetStatuses())
+			}
+
+			if renderer.IsReport() {
+				return renderer.RenderReport(drift.DependenciesReport(moduleName, resp.GetStatuses()))
+			}
+
+			return renderer.Render(resp, dependencyDriftTable(resp.GetStatuses()))
 		},
 	}
 
@@ -120,6 +337,669 @@ func newGetModuleDependencyDriftStatusCmd(client v1.DriftServiceClient) *cobra.C
 	return getCmd
 }
 
+// projectDependencyDriftStatus evaluates the dependency drift of the registry modules
+// of pbuf.yaml at their pinned tags and prints a combined table with the verdict
+func projectDependencyDriftStatus(cmd *cobra.Command, config *model.Config, client v1.DriftServiceClient) error {
+	renderer, err := newRenderer(cmd)
+	if err != nil {
+		return err
+	}
+
+	modules, err := projectDependencies(cmd.Context(), config, client)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case renderer.IsReport():
+		return renderer.RenderReport(drift.ProjectReport(modules))
+	case renderer.IsTable():
+		err = renderer.RenderTable(projectDependencyDriftTable(modules))
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "\n%s\n", drift.Verdict(modules))
+		return err
+	default:
+		return renderer.Render(drift.ProjectValue(modules), nil)
+	}
+}
+
+// projectDependencies gets the dependency drift statuses of the registry modules of pbuf.yaml at their pinned tags
+func projectDependencies(ctx context.Context, config *model.Config, client v1.DriftServiceClient) ([]*drift.ModuleDependencies, error) {
+	var modules []*drift.ModuleDependencies
+	for _, module := range config.Modules {
+		if module.Repository != "" {
+			continue
+		}
+
+		tag := module.Tag
+		req := &v1.GetModuleDependencyDriftStatusRequest{ModuleName: module.Name}
+		if tag != "" {
+			req.TagName = &tag
+		}
+
+		resp, err := client.GetModuleDependencyDriftStatus(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get dependency drift status of %s@%s: %w", module.Name, tag, err)
+		}
+
+		modules = append(modules, &drift.ModuleDependencies{
+			Name:     module.Name,
+			Tag:      tag,
+			Statuses: resp.GetStatuses(),
+		})
+	}
+
+	return modules, nil
+}
+
+func newFixDependencyDriftCmd(config *model.Config, netrcAuth *netrc.Netrc, client v1.DriftServiceClient, registryClient v1.RegistryClient) *cobra.Command {
+	fixCmd := &cobra.Command{
+		Use:   "fix",
+		Short: "Apply dependency drift recommendations",
+		Long: "Fix is a command to update the tags of pbuf.yaml modules to the targets recommended " +
+			"by the dependency drift status and vendor them again",
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			includeReview, err := cmd.Flags().GetBool("include-review")
+			if err != nil {
+				return err
+			}
+
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				return err
+			}
+
+			dependencies, err := projectDependencies(cmd.Context(), config, client)
+			if err != nil {
+				return err
+			}
+
+			fixes, skipped := drift.PlanFixes(config, dependencies, includeReview)
+
+			if len(fixes) > 0 && !dryRun {
+				drift.ApplyFixes(config, fixes)
+
+				err = config.Save()
+				if err != nil {
+					return fmt.Errorf("failed to save %s: %w", model.PbufConfigFilename, err)
+				}
+
+				err = modules.Vendor(config, netrcAuth, registryClient)
+				if err != nil {
+					return fmt.Errorf("failed to vendor: %w", err)
+				}
+			}
+
+			_, err = fmt.Fprint(cmd.OutOrStdout(), drift.FixSummary(fixes, skipped))
+			return err
+		},
+	}
+
+	fixCmd.Flags().Bool("include-review", false, "also apply the updates that need a review")
+	fixCmd.Flags().Bool("dry-run", false, "print the updates without changing pbuf.yaml")
+	return fixCmd
+}
+
+func newAckDriftEventsCmd(client v1.DriftServiceClient) *cobra.Command {
+	ackCmd := &cobra.Command{
+		Use:   "ack [event_id...]",
+		Short: "Acknowledge drift events",
+		Long: "Ack is a command to acknowledge drift events by id, or all unacknowledged events " +
+			"matching the module, tag, severity and age filters",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := driftFilter(cmd)
+			if err != nil {
+				return err
+			}
+
+			by, err := cmd.Flags().GetString("by")
+			if err != nil {
+				return err
+			}
+
+			if by == "" {
+				return fmt.Errorf("unknown acknowledger. use --by to set it")
+			}
+
+			ids := args
+			switch {
+			case len(ids) > 0 && !filter.Empty():
+				return fmt.Errorf("event ids and filters cannot be used together")
+			case len(ids) == 0 && filter.Empty():
+				return fmt.Errorf("event ids or at least one of --module, --tag, --severity and --older-than are required")
+			case len(ids) == 0:
+				ids, err = selectDriftEvents(cmd, client, filter)
+				if err != nil {
+					return err
+				}
+				if len(ids) == 0 {
+					log.Printf("no unacknowledged events match the filters")
+					return nil
+				}
+			}
+
+			var acknowledged []*v1.DriftEvent
+			var failed int
+			for _, id := range ids {
+				resp, err := client.AcknowledgeDriftEvent(cmd.Context(), &v1.AcknowledgeDriftEventRequest{
+					EventId:        id,
+					AcknowledgedBy: by,
+				})
+				if err != nil {
+					log.Printf("failed to acknowledge event %s: %v", id, err)
+					failed++
+					continue
+				}
+				acknowledged = append(acknowledged, resp.GetEvent())
+			}
+
+			if acknowledged == nil {
+				acknowledged = []*v1.DriftEvent{}
+			}
+
+			err = render(cmd, acknowledged, acknowledgedEventsTable(acknowledged))
+			if err != nil {
+				return err
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("failed to acknowledge %d of %d events", failed, len(ids))
+			}
+
+			return nil
+		},
+	}
+
+	by := ""
+	if usr, err := user.Current(); err == nil {
+		by = usr.Username
+	}
+
+	ackCmd.Flags().String("module", "", "acknowledge the events of the module name or glob, e.g. acme/*")
+	ackCmd.Flags().String("tag", "", "acknowledge the events of the tag")
+	ackCmd.Flags().String("severity", "", "acknowledge the events of the severity: "+strings.Join(drift.Severities, ", "))
+	ackCmd.Flags().String("older-than", "", "acknowledge the events detected at least this long ago, e.g. 30d or 12h")
+	ackCmd.Flags().String("by", by, "who acknowledges the events")
+	ackCmd.Flags().BoolP("yes", "y", false, "skip the confirmation of the events matching the filters")
+	return ackCmd
+}
+
+func newWatchDriftEventsCmd(client v1.DriftServiceClient) *cobra.Command {
+	watchCmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Watch drift events",
+		Long:  "Watch is a command to poll unacknowledged drift events and print the new ones as they appear",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			interval, err := cmd.Flags().GetDuration("interval")
+			if err != nil {
+				return err
+			}
+
+			skipExisting, err := cmd.Flags().GetBool("skip-existing")
+			if err != nil {
+				return err
+			}
+
+			if interval <= 0 {
+				return fmt.Errorf("interval must be positive")
+			}
+
+			renderer, err := newRenderer(cmd)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			seen := drift.NewSeen()
+			header := true
+			first := true
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				resp, err := client.ListDriftEvents(ctx, &v1.ListDriftEventsRequest{
+					UnacknowledgedOnly: true,
+				})
+				switch {
+				case ctx.Err() != nil:
+					return nil
+				case err != nil:
+					// the registry may be temporarily unavailable, the next poll retries
+					log.Printf("failed to list drift events: %v", err)
+				default:
+					events := seen.Add(resp.GetEvents())
+					if first && skipExisting {
+						events = nil
+					}
+					first = false
+
+					if len(events) > 0 {
+						err = renderDriftEventsStream(cmd, renderer, events, header)
+						if err != nil {
+							return err
+						}
+						header = false
+					}
+				}
+
+				select {
+				case <-ctx.Done():
+					return nil
+				case <-ticker.C:
+				}
+			}
+		},
+	}
+
+	watchCmd.Flags().Duration("interval", 30*time.Second, "how often to poll drift events")
+	watchCmd.Flags().Bool("skip-existing", false, "print only the events that appear after the watch starts")
+	return watchCmd
+}
+
+// renderDriftEvents writes the data with the events table,
+// or the events as findings for the sarif and junit formats
+func renderDriftEvents(cmd *cobra.Command, data any, events []*v1.DriftEvent) error {
+	renderer, err := newRenderer(cmd)
+	if err != nil {
+		return err
+	}
+
+	if renderer.IsReport() {
+		return renderer.RenderReport(drift.EventsReport(events))
+	}
+
+	return renderer.Render(data, driftEventsTable(events))
+}
+
+// renderDriftEventsStream writes the events as table rows without repeating the header,
+// or one document per event for the other formats
+func renderDriftEventsStream(cmd *cobra.Command, renderer *output.Renderer, events []*v1.DriftEvent, header bool) error {
+	if renderer.IsTable() {
+		table := driftEventsTable(events)
+		table.NoHeader = !header
+		return renderer.RenderTable(table)
+	}
+
+	for _, event := range events {
+		if renderer.Format() == output.FormatYAML {
+			if _, err := fmt.Fprintln(cmd.OutOrStdout(), "---"); err != nil {
+				return err
+			}
+		}
+		if err := renderer.Render(event, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newCheckDriftEventsCmd(config *model.Config, client v1.DriftServiceClient) *cobra.Command {
+	checkCmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check drift events",
+		Long: "Check is a command to print the unacknowledged drift events of the pbuf.yaml modules " +
+			"and fail if any of them is at or above the severity",
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			failOn, err := cmd.Flags().GetString("fail-on")
+			if err != nil {
+				return err
+			}
+
+			threshold, err := drift.ParseSeverity(failOn)
+			if err != nil {
+				return err
+			}
+
+			modules := map[string]bool{}
+			for _, module := range config.Modules {
+				if module.Repository == "" {
+					modules[module.Name] = true
+				}
+			}
+
+			resp, err := client.ListDriftEvents(cmd.Context(), &v1.ListDriftEventsRequest{
+				UnacknowledgedOnly: true,
+			})
+			if err != nil {
+				return err
+			}
+
+			events := []*v1.DriftEvent{}
+			failed := 0
+			for _, event := range resp.GetEvents() {
+				if !modules[event.GetModuleName()] {
+					continue
+				}
+				events = append(events, event)
+				if drift.AtLeast(event.GetSeverity(), threshold) {
+					failed++
+				}
+			}
+
+			renderer, err := newRenderer(cmd)
+			if err != nil {
+				return err
+			}
+
+			// the reports are written even if empty to replace the ones of the previous runs
+			if len(events) == 0 && !renderer.IsReport() {
+				log.Printf("no unacknowledged drift events for the pbuf.yaml modules")
+				return nil
+			}
+
+			err = renderDriftEvents(cmd, events, events)
+			if err != nil {
+				return err
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d unacknowledged drift events at or above %s", failed, drift.SeverityName(threshold))
+			}
+
+			return nil
+		},
+	}
+
+	checkCmd.Flags().String("fail-on", "critical", "fail on unacknowledged events at or above the severity: "+
+		strings.Join(drift.Severities, ", "))
+	return checkCmd
+}
+
+func newLocalDriftCmd(config *model.Config, client v1.DriftServiceClient, registryClient v1.RegistryClient) *cobra.Command {
+	localCmd := &cobra.Command{
+		Use:   "local",
+		Short: "Local drift",
+		Long: "Local is a command to compare the vendored registry module files with the hashes recorded in " +
+			registry.LockFilename + " and with the current registry content",
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lock, err := registry.LoadLock(registry.LockFilename)
+			if err != nil {
+				return err
+			}
+
+			files := []*drift.LocalFile{}
+			for _, module := range config.Modules {
+				if module.Repository != "" {
+					continue
+				}
+
+				locked := lock.Module(module.Name)
+				if locked == nil || locked.Tag != module.Tag {
+					files = append(files, &drift.LocalFile{
+						Module: module.Name,
+						Tag:    module.Tag,
+						Status: drift.StatusNotVendored,
+					})
+					continue
+				}
+
+				pulled, err := registryClient.PullModule(cmd.Context(), &v1.PullModuleRequest{
+					Name: module.Name,
+					Tag:  module.Tag,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to pull module %s@%s: %w", module.Name, module.Tag, err)
+				}
+
+				var upstream []*drift.UpstreamFile
+				for _, protoFile := range pulled.Protofiles {
+					path, ok := registry.VendorPath(module, protoFile.Filename)
+					if !ok {
+						continue
+					}
+					upstream = append(upstream, &drift.UpstreamFile{
+						Filename: protoFile.Filename,
+						Path:     filepath.ToSlash(path),
+						Hash:     registry.FileHash([]byte(protoFile.Content)),
+					})
+				}
+
+				tag := module.Tag
+				events, err := client.GetModuleDriftEvents(cmd.Context(), &v1.GetModuleDriftEventsRequest{
+					ModuleName: module.Name,
+					TagName:    &tag,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to get drift events of %s@%s: %w", module.Name, module.Tag, err)
+				}
+
+				changed, err := drift.CompareLocal(locked, upstream, events.GetEvents())
+				if err != nil {
+					return err
+				}
+				files = append(files, changed...)
+			}
+
+			if len(files) == 0 {
+				log.Printf("vendored files match %s and the registry", registry.LockFilename)
+				return nil
+			}
+
+			return render(cmd, files, localDriftTable(files))
+		},
+	}
+
+	return localCmd
+}
+
+func newNotifyDriftEventsCmd(config *model.Config, client v1.DriftServiceClient) *cobra.Command {
+	notifyCmd := &cobra.Command{
+		Use:   "notify",
+		Short: "Notify drift events",
+		Long: "Notify is a command to post the new unacknowledged drift events to a webhook. " +
+			"The delivered events are recorded in a state file and are not sent twice",
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			webhook, err := cmd.Flags().GetString("webhook")
+			if err != nil {
+				return err
+			}
+
+			payload, err := cmd.Flags().GetString("payload")
+			if err != nil {
+				return err
+			}
+
+			payloadTemplate, err := cmd.Flags().GetString("payload-template")
+			if err != nil {
+				return err
+			}
+
+			if payloadTemplate == "" {
+				if err := drift.CheckPayload(payload); err != nil {
+					return err
+				}
+			}
+
+			statePath, err := cmd.Flags().GetString("state")
+			if err != nil {
+				return err
+			}
+
+			minSeverity, err := cmd.Flags().GetString("min-severity")
+			if err != nil {
+				return err
+			}
+
+			timeout, err := cmd.Flags().GetDuration("timeout")
+			if err != nil {
+				return err
+			}
+
+			threshold, err := drift.ParseSeverity(minSeverity)
+			if err != nil {
+				return err
+			}
+
+			templateText := ""
+			if payloadTemplate != "" {
+				content, err := os.ReadFile(payloadTemplate)
+				if err != nil {
+					return fmt.Errorf("failed to read payload template: %w", err)
+				}
+				templateText = string(content)
+			}
+
+			if statePath == "" {
+				statePath, err = drift.NotifyStatePath(config.Registry.Addr, webhook)
+				if err != nil {
+					return err
+				}
+			}
+
+			state, err := drift.LoadNotifyState(statePath)
+			if err != nil {
+				return err
+			}
+
+			resp, err := client.ListDriftEvents(cmd.Context(), &v1.ListDriftEventsRequest{
+				UnacknowledgedOnly: true,
+			})
+			if err != nil {
+				return err
+			}
+
+			var listed []*v1.DriftEvent
+			for _, event := range resp.GetEvents() {
+				if drift.AtLeast(event.GetSeverity(), threshold) {
+					listed = append(listed, event)
+				}
+			}
+
+			pending := state.Pending(listed)
+			if len(pending) == 0 {
+				log.Printf("no new drift events to notify")
+				return nil
+			}
+
+			var body []byte
+			if templateText != "" {
+				body, err = drift.TemplatePayload(templateText, pending)
+			} else {
+				body, err = drift.Payload(payload, pending)
+			}
+			if err != nil {
+				return err
+			}
+
+			err = drift.Post(cmd.Context(), &http.Client{Timeout: timeout}, webhook, body)
+			if err != nil {
+				return err
+			}
+
+			state.MarkDelivered(pending, listed)
+			err = state.Save(statePath)
+			if err != nil {
+				return err
+			}
+
+			log.Printf("notified %d drift events", len(pending))
+			return nil
+		},
+	}
+
+	notifyCmd.Flags().String("webhook", "", "URL to post the events to")
+	notifyCmd.Flags().String("payload", drift.PayloadGeneric, "payload format: "+strings.Join(drift.Payloads, ", "))
+	notifyCmd.Flags().String("payload-template", "", "file with a Go template of the payload executed on {{.events}}")
+	notifyCmd.Flags().String("state", "", "state file with the delivered events (default in the user cache directory)")
+	notifyCmd.Flags().String("min-severity", "info", "notify only the events at or above the severity: "+strings.Join(drift.Severities, ", "))
+	notifyCmd.Flags().Duration("timeout", 30*time.Second, "webhook request timeout")
+	_ = notifyCmd.MarkFlagRequired("webhook")
+	return notifyCmd
+}
+
+// driftFilter reads the drift event filter flags
+func driftFilter(cmd *cobra.Command) (*drift.Filter, error) {
+	filter := &drift.Filter{}
+
+	var err error
+	filter.Module, err = cmd.Flags().GetString("module")
+	if err != nil {
+		return nil, err
+	}
+
+	filter.Tag, err = cmd.Flags().GetString("tag")
+	if err != nil {
+		return nil, err
+	}
+
+	severity, err := cmd.Flags().GetString("severity")
+	if err != nil {
+		return nil, err
+	}
+	if severity != "" {
+		filter.Severity, err = drift.ParseSeverity(severity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	olderThan, err := cmd.Flags().GetString("older-than")
+	if err != nil {
+		return nil, err
+	}
+	if olderThan != "" {
+		filter.OlderThan, err = drift.ParseAge(olderThan)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return filter, nil
+}
+
+// selectDriftEvents previews the unacknowledged events matching the filter on stderr
+// and returns their ids once confirmed
+func selectDriftEvents(cmd *cobra.Command, client v1.DriftServiceClient, filter *drift.Filter) ([]string, error) {
+	yes, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.ListDriftEvents(cmd.Context(), &v1.ListDriftEventsRequest{
+		UnacknowledgedOnly: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := filter.Select(resp.GetEvents(), time.Now())
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	log.Printf("the following %d events will be acknowledged:", len(events))
+	preview, err := output.NewRenderer(cmd.ErrOrStderr(), string(output.FormatTable), "")
+	if err != nil {
+		return nil, err
+	}
+	if err := preview.RenderTable(driftEventsTable(events)); err != nil {
+		return nil, err
+	}
+
+	if !yes {
+		if err := confirm(cmd, "acknowledge?", "acknowledgement aborted"); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]string, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.GetId())
+	}
+	return ids, nil
+}
+
 func printDependencyDriftStatuses(w io.Writer, moduleName, tagName string, statuses []*v1.DependencyDriftStatus) error {
 	if _, err := fmt.Fprintf(w, "Dependency drift status for %s", moduleName); err != nil {
 		return err
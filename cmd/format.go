@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/pbufio/pbuf-cli/internal/diff"
+	"github.com/pbufio/pbuf-cli/internal/format"
+	"github.com/pbufio/pbuf-cli/internal/model"
+	"github.com/pbufio/pbuf-cli/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+// NewFormatCmd creates cobra command for format
+func NewFormatCmd(config *model.Config) *cobra.Command {
+	formatCmd := &cobra.Command{
+		Use:   "format [paths...]",
+		Short: "Format",
+		Long:  "Format is a command to rewrite proto files into the canonical style. Exported paths are used by default",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			write, err := cmd.Flags().GetBool("write")
+			if err != nil {
+				return err
+			}
+
+			showDiff, err := cmd.Flags().GetBool("diff")
+			if err != nil {
+				return err
+			}
+
+			check, err := cmd.Flags().GetBool("check")
+			if err != nil {
+				return err
+			}
+
+			paths := args
+			if len(paths) == 0 {
+				paths = config.Export.Paths
+			}
+
+			protoFiles, err := registry.CollectProtoFilesInDirs(paths)
+			if err != nil {
+				return fmt.Errorf("failed to collect proto files: %w", err)
+			}
+
+			if len(args) == 0 {
+				protoFiles = registry.ExcludeProtoFiles(protoFiles, config.Export.Exclude)
+			}
+
+			out := cmd.OutOrStdout()
+			var unformatted []string
+
+			for _, protoFile := range protoFiles {
+				formatted, err := format.Format(protoFile.Filename, protoFile.Content)
+				if err != nil {
+					return err
+				}
+
+				if formatted == protoFile.Content {
+					continue
+				}
+
+				unformatted = append(unformatted, protoFile.Filename)
+
+				switch {
+				case showDiff:
+					_, err = io.WriteString(out, diff.Unified(
+						"a/"+protoFile.Filename, "b/"+protoFile.Filename, protoFile.Content, formatted))
+				case check:
+					_, err = fmt.Fprintln(out, protoFile.Filename)
+				case !write:
+					_, err = io.WriteString(out, formatted)
+				}
+				if err != nil {
+					return err
+				}
+
+				if write {
+					err = os.WriteFile(protoFile.Filename, []byte(formatted), 0644)
+					if err != nil {
+						return fmt.Errorf("failed to write %s: %w", protoFile.Filename, err)
+					}
+					log.Printf("formatted %s", protoFile.Filename)
+				}
+			}
+
+			if check && len(unformatted) > 0 {
+				return fmt.Errorf("%d files are not formatted. run `pbuf format -w`", len(unformatted))
+			}
+
+			return nil
+		},
+	}
+
+	formatCmd.Flags().BoolP("write", "w", false, "write the result to the files instead of stdout")
+	formatCmd.Flags().BoolP("diff", "d", false, "print diffs instead of the formatted files")
+	formatCmd.Flags().Bool("check", false, "list unformatted files and fail if any")
+
+	return formatCmd
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/pbufio/pbuf-cli/internal/generate"
+	"github.com/pbufio/pbuf-cli/internal/model"
+	"github.com/spf13/cobra"
+)
+
+// NewGenerateCmd creates cobra command for generate
+func NewGenerateCmd(config *model.Config) *cobra.Command {
+	generateCmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate",
+		Long:  "Generate is a command to run protoc plugins from pbuf.yaml on the vendored and exported proto files",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return generate.Generate(cmd.Context(), config)
+		},
+	}
+
+	return generateCmd
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/pbufio/pbuf-cli/internal/lint"
+	"github.com/pbufio/pbuf-cli/internal/model"
+	"github.com/pbufio/pbuf-cli/internal/registry"
+	"github.com/pbufio/pbuf-cli/internal/schema"
+	"github.com/spf13/cobra"
+)
+
+// NewLintCmd creates cobra command for lint
+func NewLintCmd(config *model.Config) *cobra.Command {
+	lintCmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Lint",
+		Long:  "Lint is a command to check the exported proto files with the lint rules",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			listRules, err := cmd.Flags().GetBool("list-rules")
+			if err != nil {
+				return err
+			}
+
+			if listRules {
+				for _, name := range lint.RuleNames() {
+					if _, err := fmt.Fprintln(cmd.OutOrStdout(), name); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
+			protoFiles, err := registry.CollectProtoFilesInDirs(config.Export.Paths)
+			if err != nil {
+				return fmt.Errorf("failed to collect proto files: %w", err)
+			}
+			protoFiles = registry.ExcludeProtoFiles(protoFiles, config.Export.Exclude)
+
+			files, err := schema.ParseAll(protoFiles)
+			if err != nil {
+				return err
+			}
+
+			issues, err := lint.Lint(files, config.Lint)
+			if err != nil {
+				return err
+			}
+
+			// --format is kept for the github annotations and the existing scripts
+			if cmd.Flags().Changed("format") {
+				format, err := cmd.Flags().GetString("format")
+				if err != nil {
+					return err
+				}
+
+				err = lint.Write(cmd.OutOrStdout(), issues, format)
+				if err != nil {
+					return err
+				}
+			} else {
+				lines := make([]string, 0, len(issues))
+				for _, issue := range issues {
+					lines = append(lines, issue.String())
+				}
+
+				if issues == nil {
+					issues = []*lint.Issue{}
+				}
+
+				err = renderFindings(cmd, issues, lines)
+				if err != nil {
+					return err
+				}
+			}
+
+			if len(issues) > 0 {
+				return fmt.Errorf("%d lint issues found", len(issues))
+			}
+
+			return nil
+		},
+	}
+
+	lintCmd.Flags().String("format", lint.FormatText, "lint output format: text|json|github, overrides --output")
+	lintCmd.Flags().Bool("list-rules", false, "list available rules")
+
+	return lintCmd
+}
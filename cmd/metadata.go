@@ -1,7 +1,14 @@
 package cmd
 
 import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
 	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/metadata"
 	"github.com/pbufio/pbuf-cli/internal/model"
 	"github.com/spf13/cobra"
 )
@@ -17,28 +24,83 @@ func NewMetadataCmd(config *model.Config, client v1.MetadataServiceClient) *cobr
 	}
 
 	metadataCmd.AddCommand(newGetMetadataCmd(config, client))
+	metadataCmd.AddCommand(newMetadataTreeCmd(config, client))
+	metadataCmd.AddCommand(newMetadataDocsCmd(config, client))
+	metadataCmd.AddCommand(newMetadataDiffCmd(client))
 
 	return metadataCmd
 }
 
 func newGetMetadataCmd(config *model.Config, client v1.MetadataServiceClient) *cobra.Command {
 	getCmd := &cobra.Command{
-		Use:   "get [module_name] [tag]",
-		Short: "Get metadata",
-		Long:  "Get is a command to get parsed metadata (packages) for a module tag",
+		Use:         "get [module_name] [tag]",
+		Short:       "Get metadata",
+		Long:        "Get is a command to get parsed metadata (packages) for a module tag",
+		Args:        cobra.RangeArgs(0, 2),
+		Annotations: jsonByDefault(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			moduleName, tag := metadataArgs(config, args)
+			if moduleName == "" {
+				return cmd.Help()
+			}
+
+			resp, err := client.GetMetadata(cmd.Context(), &v1.GetMetadataRequest{
+				Name: moduleName,
+				Tag:  tag,
+			})
+			if err != nil {
+				return err
+			}
+
+			return render(cmd, resp, packagesTable(resp.Packages))
+		},
+	}
+
+	return getCmd
+}
+
+func newMetadataTreeCmd(config *model.Config, client v1.MetadataServiceClient) *cobra.Command {
+	treeCmd := &cobra.Command{
+		Use:   "tree [module_name] [tag]",
+		Short: "Metadata tree",
+		Long:  "Tree is a command to print module metadata as a tree of packages, files, services and messages",
 		Args:  cobra.RangeArgs(0, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			moduleName := config.Name
-			if len(args) > 0 {
-				moduleName = args[0]
+			moduleName, tag := metadataArgs(config, args)
+			if moduleName == "" {
+				return cmd.Help()
+			}
+
+			resp, err := client.GetMetadata(cmd.Context(), &v1.GetMetadataRequest{
+				Name: moduleName,
+				Tag:  tag,
+			})
+			if err != nil {
+				return err
 			}
+
+			return metadata.WriteTree(cmd.OutOrStdout(), resp.Packages)
+		},
+	}
+
+	return treeCmd
+}
+
+func newMetadataDocsCmd(config *model.Config, client v1.MetadataServiceClient) *cobra.Command {
+	docsCmd := &cobra.Command{
+		Use:   "docs [module_name] [tag]",
+		Short: "Metadata docs",
+		Long:  "Docs is a command to write Markdown API reference pages for module metadata, one page per package",
+		Args:  cobra.RangeArgs(0, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			moduleName, tag := metadataArgs(config, args)
 			if moduleName == "" {
 				return cmd.Help()
 			}
 
-			tag := ""
-			if len(args) > 1 {
-				tag = args[1]
+			out, err := cmd.Flags().GetString("out")
+			if err != nil {
+				return err
 			}
 
 			resp, err := client.GetMetadata(cmd.Context(), &v1.GetMetadataRequest{
@@ -49,9 +111,98 @@ func newGetMetadataCmd(config *model.Config, client v1.MetadataServiceClient) *c
 				return err
 			}
 
-			return printJSON(resp)
+			title := moduleName
+			if tag != "" {
+				title += " " + tag
+			}
+
+			err = os.MkdirAll(out, 0755)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", out, err)
+			}
+
+			pages := metadata.Markdown(title, resp.Packages)
+
+			names := make([]string, 0, len(pages))
+			for name := range pages {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				filename := filepath.Join(out, name)
+				err = os.WriteFile(filename, []byte(pages[name]), 0644)
+				if err != nil {
+					return fmt.Errorf("failed to write %s: %w", filename, err)
+				}
+				log.Printf("written %s", filename)
+			}
+
+			return nil
 		},
 	}
 
-	return getCmd
+	docsCmd.Flags().String("out", "docs", "folder to write the pages to")
+	return docsCmd
+}
+
+func newMetadataDiffCmd(client v1.MetadataServiceClient) *cobra.Command {
+	diffCmd := &cobra.Command{
+		Use:   "diff [module_name] [from_tag] [to_tag]",
+		Short: "Metadata diff",
+		Long: "Diff is a command to report the messages, fields, enums and RPCs added, removed or changed " +
+			"between two module tags as a changelog",
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			moduleName, fromTag, toTag := args[0], args[1], args[2]
+
+			renderer, err := newRenderer(cmd)
+			if err != nil {
+				return err
+			}
+
+			from, err := client.GetMetadata(cmd.Context(), &v1.GetMetadataRequest{
+				Name: moduleName,
+				Tag:  fromTag,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to get metadata for %s: %w", fromTag, err)
+			}
+
+			to, err := client.GetMetadata(cmd.Context(), &v1.GetMetadataRequest{
+				Name: moduleName,
+				Tag:  toTag,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to get metadata for %s: %w", toTag, err)
+			}
+
+			changes := metadata.Diff(from.Packages, to.Packages)
+
+			if renderer.IsTable() {
+				title := fmt.Sprintf("%s %s...%s", moduleName, fromTag, toTag)
+				return metadata.WriteChangelog(cmd.OutOrStdout(), title, changes)
+			}
+
+			return renderer.Render(changes, nil)
+		},
+	}
+
+	return diffCmd
+}
+
+// metadataArgs returns the module name and the tag from the arguments.
+// The module name from pbuf.yaml is used by default
+func metadataArgs(config *model.Config, args []string) (string, string) {
+	moduleName := config.Name
+	if len(args) > 0 {
+		moduleName = args[0]
+	}
+
+	tag := ""
+	if len(args) > 1 {
+		tag = args[1]
+	}
+
+	return moduleName, tag
 }
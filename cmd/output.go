@@ -0,0 +1,427 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/access"
+	"github.com/pbufio/pbuf-cli/internal/drift"
+	"github.com/pbufio/pbuf-cli/internal/output"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// defaultOutputAnnotation is the command annotation with the default output format
+const defaultOutputAnnotation = "default-output"
+
+// addOutputFlags adds the global output flags to the root command
+func addOutputFlags(rootCmd *cobra.Command) {
+	var formats []string
+	for _, format := range output.Formats {
+		formats = append(formats, string(format))
+	}
+
+	rootCmd.PersistentFlags().StringP("output", "o", "",
+		"output format: "+strings.Join(formats, "|")+" (default table, json for the commands that printed json before)")
+	rootCmd.PersistentFlags().String("template", "", "Go template for the template output, e.g. {{.name}}")
+}
+
+// jsonByDefault returns the annotations of the commands that printed json
+// before the --output flag was added, so their default output stays the same
+func jsonByDefault() map[string]string {
+	return map[string]string{defaultOutputAnnotation: string(output.FormatJSON)}
+}
+
+// newRenderer creates the renderer writing to the command stdout
+func newRenderer(cmd *cobra.Command) (*output.Renderer, error) {
+	format, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return nil, err
+	}
+
+	if format == "" {
+		format = string(output.FormatTable)
+		if value, ok := cmd.Annotations[defaultOutputAnnotation]; ok {
+			format = value
+		}
+	}
+
+	text, err := cmd.Flags().GetString("template")
+	if err != nil {
+		return nil, err
+	}
+
+	return output.NewRenderer(cmd.OutOrStdout(), format, text)
+}
+
+// render writes the data with the renderer of the command
+func render(cmd *cobra.Command, data any, table *output.Table) error {
+	renderer, err := newRenderer(cmd)
+	if err != nil {
+		return err
+	}
+
+	return renderer.Render(data, table)
+}
+
+// renderFindings writes the findings of lint and breaking
+// as `file:line: rule message` lines for the table formats
+// and as the data for the other formats
+func renderFindings(cmd *cobra.Command, data any, lines []string) error {
+	renderer, err := newRenderer(cmd)
+	if err != nil {
+		return err
+	}
+
+	if !renderer.IsTable() {
+		return renderer.Render(data, nil)
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(cmd.OutOrStdout(), line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func modulesTable(modules ...*v1.Module) *output.Table {
+	table := &output.Table{
+		Columns: []output.Column{
+			{Name: "NAME"},
+			{Name: "LATEST TAG"},
+			{Name: "DRAFT TAGS"},
+			{Name: "PACKAGES"},
+			{Name: "ID", Wide: true},
+			{Name: "TAGS", Wide: true},
+		},
+	}
+
+	for _, module := range modules {
+		latestTag := "-"
+		if len(module.GetTags()) > 0 {
+			latestTag = module.GetTags()[0]
+		}
+
+		table.Rows = append(table.Rows, []string{
+			module.GetName(),
+			latestTag,
+			strconv.Itoa(len(module.GetDraftTags())),
+			strconv.Itoa(len(module.GetPackages())),
+			module.GetId(),
+			strings.Join(module.GetTags(), ","),
+		})
+	}
+
+	return table
+}
+
+func dependenciesTable(dependencies []*v1.Dependency) *output.Table {
+	table := &output.Table{
+		Columns: []output.Column{{Name: "DEPENDENCY"}, {Name: "TAG"}},
+	}
+
+	for _, dependency := range dependencies {
+		table.Rows = append(table.Rows, []string{dependency.GetName(), dependency.GetTag()})
+	}
+
+	return table
+}
+
+func usersTable(users ...*v1.User) *output.Table {
+	table := &output.Table{
+		Columns: []output.Column{
+			{Name: "ID"},
+			{Name: "NAME"},
+			{Name: "TYPE"},
+			{Name: "ACTIVE"},
+			{Name: "CREATED", Wide: true},
+			{Name: "UPDATED", Wide: true},
+		},
+	}
+
+	for _, user := range users {
+		table.Rows = append(table.Rows, []string{
+			user.GetId(),
+			user.GetName(),
+			strings.ToLower(strings.TrimPrefix(user.GetType().String(), "USER_TYPE_")),
+			strconv.FormatBool(user.GetIsActive()),
+			formatTimestamp(user.GetCreatedAt()),
+			formatTimestamp(user.GetUpdatedAt()),
+		})
+	}
+
+	return table
+}
+
+func permissionsTable(entries ...*v1.ACLEntry) *output.Table {
+	table := &output.Table{
+		Columns: []output.Column{
+			{Name: "USER ID"},
+			{Name: "MODULE"},
+			{Name: "PERMISSION"},
+			{Name: "ID", Wide: true},
+			{Name: "CREATED", Wide: true},
+		},
+	}
+
+	for _, entry := range entries {
+		table.Rows = append(table.Rows, []string{
+			entry.GetUserId(),
+			entry.GetModuleName(),
+			strings.ToLower(strings.TrimPrefix(entry.GetPermission().String(), "PERMISSION_")),
+			entry.GetId(),
+			formatTimestamp(entry.GetCreatedAt()),
+		})
+	}
+
+	return table
+}
+
+func accessPlanTable(steps []*access.Step, applied bool) *output.Table {
+	table := &output.Table{
+		Columns: []output.Column{
+			{Name: "ACTION"},
+			{Name: "USER"},
+			{Name: "MODULE"},
+			{Name: "CHANGE"},
+		},
+	}
+	if applied {
+		table.Columns = append(table.Columns, output.Column{Name: "USER ID"}, output.Column{Name: "TOKEN"})
+	}
+
+	for _, step := range steps {
+		row := []string{step.Action, step.User, step.Module, step.Describe()}
+		if applied {
+			row = append(row, step.UserID, step.Token)
+		}
+		table.Rows = append(table.Rows, row)
+	}
+
+	return table
+}
+
+// accessPlanValue is the plan in the form rendered by the json, yaml and template formats
+func accessPlanValue(steps []*access.Step) []map[string]any {
+	values := make([]map[string]any, 0, len(steps))
+	for _, step := range steps {
+		value := map[string]any{
+			"action": step.Action,
+			"user":   step.User,
+		}
+		if step.UserID != "" {
+			value["user_id"] = step.UserID
+		}
+		if step.Module != "" {
+			value["module"] = step.Module
+		}
+		if change := step.Describe(); change != "" {
+			value["change"] = change
+		}
+		if step.Token != "" {
+			value["token"] = step.Token
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+func driftEventsTable(events []*v1.DriftEvent) *output.Table {
+	table := &output.Table{
+		Columns: []output.Column{
+			{Name: "ID"},
+			{Name: "MODULE"},
+			{Name: "TAG"},
+			{Name: "FILE"},
+			{Name: "EVENT"},
+			{Name: "SEVERITY"},
+			{Name: "DETECTED"},
+			{Name: "ACKNOWLEDGED"},
+			{Name: "PREVIOUS HASH", Wide: true},
+			{Name: "CURRENT HASH", Wide: true},
+			{Name: "ACKNOWLEDGED BY", Wide: true},
+		},
+	}
+
+	for _, event := range events {
+		table.Rows = append(table.Rows, []string{
+			event.GetId(),
+			event.GetModuleName(),
+			event.GetTagName(),
+			event.GetFilename(),
+			strings.TrimPrefix(event.GetEventType().String(), "DRIFT_EVENT_TYPE_"),
+			strings.TrimPrefix(event.GetSeverity().String(), "DRIFT_SEVERITY_"),
+			formatTimestamp(event.GetDetectedAt()),
+			strconv.FormatBool(event.GetAcknowledged()),
+			event.GetPreviousHash(),
+			event.GetCurrentHash(),
+			event.GetAcknowledgedBy(),
+		})
+	}
+
+	return table
+}
+
+func acknowledgedEventsTable(events []*v1.DriftEvent) *output.Table {
+	table := &output.Table{
+		Columns: []output.Column{
+			{Name: "ID"},
+			{Name: "MODULE"},
+			{Name: "TAG"},
+			{Name: "FILE"},
+			{Name: "SEVERITY"},
+			{Name: "ACKNOWLEDGED BY"},
+			{Name: "ACKNOWLEDGED AT"},
+		},
+	}
+
+	for _, event := range events {
+		table.Rows = append(table.Rows, []string{
+			event.GetId(),
+			event.GetModuleName(),
+			event.GetTagName(),
+			event.GetFilename(),
+			strings.TrimPrefix(event.GetSeverity().String(), "DRIFT_SEVERITY_"),
+			event.GetAcknowledgedBy(),
+			formatTimestamp(event.GetAcknowledgedAt()),
+		})
+	}
+
+	return table
+}
+
+func localDriftTable(files []*drift.LocalFile) *output.Table {
+	table := &output.Table{
+		Columns: []output.Column{
+			{Name: "MODULE"},
+			{Name: "FILE"},
+			{Name: "STATUS"},
+			{Name: "EVENTS"},
+			{Name: "REGISTRY FILE", Wide: true},
+		},
+	}
+
+	for _, file := range files {
+		table.Rows = append(table.Rows, []string{
+			file.Module + "@" + file.Tag,
+			file.Path,
+			file.Status,
+			strings.Join(file.Events, ","),
+			file.Filename,
+		})
+	}
+
+	return table
+}
+
+func packagesTable(packages []*v1.Package) *output.Table {
+	table := &output.Table{
+		Columns: []output.Column{
+			{Name: "PACKAGE"},
+			{Name: "FILES"},
+			{Name: "MESSAGES"},
+			{Name: "SERVICES"},
+			{Name: "DEPENDENCIES", Wide: true},
+		},
+	}
+
+	for _, pkg := range packages {
+		messages, services := 0, 0
+		for _, file := range pkg.GetProtoFiles() {
+			messages += len(file.GetMessages())
+			services += len(file.GetServices())
+		}
+
+		var dependencies []string
+		for _, dependency := range pkg.GetDependencies() {
+			dependencies = append(dependencies, dependency.GetName())
+		}
+
+		table.Rows = append(table.Rows, []string{
+			pkg.GetName(),
+			strconv.Itoa(len(pkg.GetProtoFiles())),
+			strconv.Itoa(messages),
+			strconv.Itoa(services),
+			strings.Join(dependencies, ","),
+		})
+	}
+
+	return table
+}
+
+func dependencyDriftTable(statuses []*v1.DependencyDriftStatus) *output.Table {
+	table := &output.Table{
+		Columns: []output.Column{
+			{Name: "DEPENDENCY"},
+			{Name: "CURRENT"},
+			{Name: "TARGET"},
+			{Name: "SEVERITY"},
+			{Name: "RECOMMENDATION"},
+		},
+	}
+
+	for _, status := range statuses {
+		table.Rows = append(table.Rows, []string{
+			status.GetDependencyName(),
+			status.GetCurrentTag(),
+			status.GetTargetTag(),
+			status.GetSeverity().String(),
+			status.GetRecommendation().String(),
+		})
+	}
+
+	return table
+}
+
+func projectDependencyDriftTable(modules []*drift.ModuleDependencies) *output.Table {
+	table := &output.Table{
+		Columns: []output.Column{
+			{Name: "MODULE"},
+			{Name: "DEPENDENCY"},
+			{Name: "CURRENT"},
+			{Name: "TARGET"},
+			{Name: "SEVERITY"},
+			{Name: "RECOMMENDATION"},
+		},
+	}
+
+	for _, module := range modules {
+		if len(module.Statuses) == 0 {
+			table.Rows = append(table.Rows, []string{module.Name + "@" + module.Tag, "-", "", "", "", "NO DRIFT"})
+			continue
+		}
+
+		for _, status := range module.Statuses {
+			table.Rows = append(table.Rows, []string{
+				module.Name + "@" + module.Tag,
+				status.GetDependencyName(),
+				status.GetCurrentTag(),
+				status.GetTargetTag(),
+				status.GetSeverity().String(),
+				status.GetRecommendation().String(),
+			})
+		}
+	}
+
+	return table
+}
+
+// valueTable is a single row table for simple responses
+func valueTable(name string, value any) *output.Table {
+	return &output.Table{
+		Columns: []output.Column{{Name: name}},
+		Rows:    [][]string{{fmt.Sprint(value)}},
+	}
+}
+
+func formatTimestamp(timestamp *timestamppb.Timestamp) string {
+	if timestamp == nil {
+		return "-"
+	}
+	return timestamp.AsTime().Format(time.RFC3339)
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/model"
+	"github.com/pbufio/pbuf-cli/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+// skipPush compares the content with the pushed tags and reports whether the push is not needed,
+// and whether the existing draft tag has to be replaced.
+// With --if-not-exists an existing tag with the same content is skipped.
+// With replaceDraft an existing draft tag with the same content is skipped,
+// and a draft tag with different content is reported to be replaced: the caller deletes it right before the push.
+// Otherwise, the content equal to the latest tag is skipped, or fails with --if-changed.
+// With force the content equal to the latest tag is pushed anyway
+func skipPush(
+	cmd *cobra.Command,
+	config *model.Config,
+	client v1.RegistryClient,
+	tag string,
+	protoFiles []*v1.ProtoFile,
+	dependencies []*v1.Dependency,
+	force bool,
+	replaceDraft bool,
+) (bool, bool, error) {
+	ifChanged, err := cmd.Flags().GetBool("if-changed")
+	if err != nil {
+		return false, false, err
+	}
+
+	ifNotExists, err := cmd.Flags().GetBool("if-not-exists")
+	if err != nil {
+		return false, false, err
+	}
+
+	module, err := client.GetModule(cmd.Context(), &v1.GetModuleRequest{
+		Name:             config.Name,
+		IncludeDraftTags: true,
+	})
+	if err != nil {
+		return false, false, fmt.Errorf("failed to get module %s: %w", config.Name, err)
+	}
+
+	hash := registry.ContentHash(protoFiles, dependencies)
+
+	isDraftTag := slices.Contains(module.DraftTags, tag)
+	if (ifNotExists || replaceDraft) && (slices.Contains(module.Tags, tag) || isDraftTag) {
+		tagHash, err := tagContentHash(cmd.Context(), client, config.Name, tag)
+		if err != nil {
+			return false, false, err
+		}
+
+		if tagHash != hash && replaceDraft && isDraftTag {
+			log.Printf("draft tag %s has different content. replacing it", tag)
+			return false, true, nil
+		}
+
+		if tagHash != hash {
+			return false, false, fmt.Errorf("tag %s already exists with different content", tag)
+		}
+
+		log.Printf("tag %s already exists with the same content. nothing to push", tag)
+		return true, false, nil
+	}
+
+	if force || len(module.Tags) == 0 {
+		return false, false, nil
+	}
+
+	latest := module.Tags[0]
+	latestHash, err := tagContentHash(cmd.Context(), client, config.Name, latest)
+	if err != nil {
+		return false, false, err
+	}
+
+	if latestHash != hash {
+		return false, false, nil
+	}
+
+	if ifChanged {
+		return false, false, fmt.Errorf("content is identical to tag %s. nothing to push", latest)
+	}
+
+	log.Printf("content is identical to tag %s. skipping push. use --force to push it under a new tag", latest)
+	return true, false, nil
+}
+
+// tagContentHash returns the content hash of the pushed module tag
+func tagContentHash(ctx context.Context, client v1.RegistryClient, name, tag string) (string, error) {
+	pulled, err := client.PullModule(ctx, &v1.PullModuleRequest{
+		Name: name,
+		Tag:  tag,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to pull module %s@%s: %w", name, tag, err)
+	}
+
+	dependencies, err := client.GetModuleDependencies(ctx, &v1.GetModuleDependenciesRequest{
+		Name: name,
+		Tag:  tag,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get dependencies of %s@%s: %w", name, tag, err)
+	}
+
+	return registry.ContentHash(pulled.Protofiles, dependencies.Dependencies), nil
+}
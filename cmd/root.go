@@ -1,15 +1,17 @@
 package cmd
 
 import (
-	"encoding/json"
+	"fmt"
 	"log"
 	"net"
 	"os"
 	"os/user"
 	"path/filepath"
+	"slices"
 
 	"github.com/jdx/go-netrc"
 	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/breaking"
 	"github.com/pbufio/pbuf-cli/internal/model"
 	"github.com/pbufio/pbuf-cli/internal/modules"
 	"github.com/pbufio/pbuf-cli/internal/registry"
@@ -40,6 +42,8 @@ func NewRootCmd() *cobra.Command {
 		},
 	}
 
+	addOutputFlags(rootCmd)
+
 	if configNotFound {
 		rootCmd.AddCommand(CreateInitCmd())
 		return rootCmd
@@ -77,12 +81,19 @@ func NewRootCmd() *cobra.Command {
 		rootCmd.AddCommand(NewVendorCmd(modulesConfig, netrcAuth, registryClient))
 		rootCmd.AddCommand(NewAuthCmd(modulesConfig, usr, netrcAuth))
 		rootCmd.AddCommand(NewUsersCmd(modulesConfig, usersClient))
-		rootCmd.AddCommand(NewDriftCmd(modulesConfig, driftClient))
+		rootCmd.AddCommand(NewDriftCmd(modulesConfig, netrcAuth, driftClient, registryClient))
 		rootCmd.AddCommand(NewMetadataCmd(modulesConfig, metadataClient))
+		rootCmd.AddCommand(NewBreakingCmd(modulesConfig, registryClient))
+		rootCmd.AddCommand(NewSearchCmd(modulesConfig, registryClient, metadataClient))
+		rootCmd.AddCommand(NewBrowseCmd(modulesConfig, registryClient, metadataClient))
 	} else {
 		rootCmd.AddCommand(NewVendorCmd(modulesConfig, netrcAuth, nil))
 	}
 
+	rootCmd.AddCommand(NewGenerateCmd(modulesConfig))
+	rootCmd.AddCommand(NewLintCmd(modulesConfig))
+	rootCmd.AddCommand(NewFormatCmd(modulesConfig))
+
 	return rootCmd
 }
 
@@ -139,6 +150,7 @@ func NewModuleCmd(config *model.Config, client v1.RegistryClient) *cobra.Command
 	// add subcommands
 	moduleCmd.AddCommand(NewRegisterModuleCmd(config, client))
 	moduleCmd.AddCommand(NewPushModuleCmd(config, client))
+	moduleCmd.AddCommand(NewPromoteModuleCmd(config, client))
 	moduleCmd.AddCommand(NewDeleteTagCmd(config, client))
 	moduleCmd.AddCommand(NewDeleteModuleCmd(config, client))
 
@@ -182,26 +194,60 @@ func NewPushModuleCmd(config *model.Config, client v1.RegistryClient) *cobra.Com
 	pushModuleCmd := &cobra.Command{
 		Use:   "push [tag] [flags]",
 		Short: "Push",
-		Long:  "Push is a command to push modules",
-		Args:  cobra.ExactArgs(1),
+		Long:  "Push is a command to push modules. The tag can be derived with --bump or --from-git instead of the argument",
+		Args:  cobra.RangeArgs(0, 1),
 		Run: func(cmd *cobra.Command, args []string) {
 			if config.Name == "" {
 				log.Fatalf("module name is required. see pbuf.yaml reference")
 			}
 
-			tag := args[0]
-
 			isDraft, err := cmd.Flags().GetBool("draft")
 			if err != nil {
 				log.Fatalf("failed to get draft flag: %v", err)
 				return
 			}
 
-			protoFiles, err := registry.CollectProtoFilesInDirs(config.Export.Paths)
+			draftFromBranch, err := cmd.Flags().GetBool("draft-from-branch")
+			if err != nil {
+				log.Fatalf("failed to get draft-from-branch flag: %v", err)
+			}
+			isDraft = isDraft || draftFromBranch
+
+			protoFiles, err := registry.CollectExportedProtoFiles(config.Export)
 			if err != nil {
 				log.Fatalf("failed to collect proto files: %v", err)
 			}
 
+			force, err := cmd.Flags().GetBool("force")
+			if err != nil {
+				log.Fatalf("failed to get force flag: %v", err)
+			}
+
+			skipValidation, err := cmd.Flags().GetBool("skip-validation")
+			if err != nil {
+				log.Fatalf("failed to get skip-validation flag: %v", err)
+			}
+
+			checkBreaking, err := cmd.Flags().GetBool("breaking")
+			if err != nil {
+				log.Fatalf("failed to get breaking flag: %v", err)
+			}
+
+			rules, err := cmd.Flags().GetString("rules")
+			if err != nil {
+				log.Fatalf("failed to get rules flag: %v", err)
+			}
+
+			ruleSet, err := breaking.ParseRuleSet(rules)
+			if err != nil {
+				log.Fatalf("%v", err)
+			}
+
+			tag, err := resolvePushTag(cmd, config, client, args, protoFiles, ruleSet, skipValidation)
+			if err != nil {
+				log.Fatalf("failed to resolve tag: %v", err)
+			}
+
 			var dependencies []*v1.Dependency
 			for _, dependency := range config.Modules {
 				if dependency.Name != "" && dependency.Repository == "" {
@@ -212,6 +258,46 @@ func NewPushModuleCmd(config *model.Config, client v1.RegistryClient) *cobra.Com
 				}
 			}
 
+			skip, replaceDraft, err := skipPush(cmd, config, client, tag, protoFiles, dependencies, force, draftFromBranch)
+			if err != nil {
+				log.Fatalf("failed to compare with pushed tags: %v", err)
+			}
+
+			if skip {
+				return
+			}
+
+			var breakingRuleSet breaking.RuleSet
+			if checkBreaking {
+				breakingRuleSet = ruleSet
+			}
+
+			problems, err := validatePush(cmd.Context(), config, client, protoFiles, breakingRuleSet)
+			if err != nil {
+				log.Fatalf("failed to validate module: %v", err)
+			}
+
+			for _, problem := range problems {
+				log.Printf("%s", problem)
+			}
+
+			if len(problems) > 0 {
+				if !skipValidation {
+					log.Fatalf("module validation failed with %d problems. use --skip-validation to push anyway", len(problems))
+				}
+				log.Printf("module validation failed with %d problems. pushing anyway", len(problems))
+			}
+
+			if replaceDraft {
+				_, err = client.DeleteModuleTag(cmd.Context(), &v1.DeleteModuleTagRequest{
+					Name: config.Name,
+					Tag:  tag,
+				})
+				if err != nil {
+					log.Fatalf("failed to delete draft tag %s: %v", tag, err)
+				}
+			}
+
 			log.Printf("pushing module %s with tag %s", config.Name, tag)
 
 			module, err := client.PushModule(cmd.Context(), &v1.PushModuleRequest{
@@ -231,30 +317,134 @@ func NewPushModuleCmd(config *model.Config, client v1.RegistryClient) *cobra.Com
 	}
 
 	pushModuleCmd.PersistentFlags().Bool("draft", false, "push draft module")
+	pushModuleCmd.PersistentFlags().Bool("force", false, "push even if the content is identical to the latest tag")
+	pushModuleCmd.PersistentFlags().Bool("skip-validation", false, "push even if the validation or the bump check fails")
+	pushModuleCmd.PersistentFlags().Bool("breaking", false, "check breaking changes against the latest tag")
+	pushModuleCmd.PersistentFlags().String("rules", string(breaking.RuleSetSource), "breaking rule set: wire|source")
+	pushModuleCmd.PersistentFlags().String("bump", "", "push the next version of the latest tag: patch|minor|major|auto")
+	pushModuleCmd.PersistentFlags().Bool("from-git", false, "push the semver git tag of the current commit")
+	pushModuleCmd.PersistentFlags().Bool("draft-from-branch", false, "push draft module tagged with the current git branch name")
+	pushModuleCmd.PersistentFlags().Bool("if-changed", false, "fail if the content is identical to the latest tag")
+	pushModuleCmd.PersistentFlags().Bool("if-not-exists", false, "do nothing if the tag already exists with the same content")
 
 	return pushModuleCmd
 }
 
+// NewPromoteModuleCmd creates cobra command for promote module
+func NewPromoteModuleCmd(config *model.Config, client v1.RegistryClient) *cobra.Command {
+	// create promote module command
+	promoteModuleCmd := &cobra.Command{
+		Use:   "promote [draft_tag] [tag]",
+		Short: "Promote",
+		Long:  "Promote is a command to push the exact content of a draft tag as a release tag and delete the draft tag",
+		Args:  cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if config.Name == "" {
+				log.Fatalf("module name is required. see pbuf.yaml reference")
+			}
+
+			draftTag, tag := args[0], args[1]
+
+			keepDraft, err := cmd.Flags().GetBool("keep-draft")
+			if err != nil {
+				log.Fatalf("failed to get keep-draft flag: %v", err)
+			}
+
+			module, err := client.GetModule(cmd.Context(), &v1.GetModuleRequest{
+				Name:             config.Name,
+				IncludeDraftTags: true,
+			})
+			if err != nil {
+				log.Fatalf("failed to get module: %v", err)
+			}
+
+			if !slices.Contains(module.DraftTags, draftTag) {
+				log.Fatalf("draft tag %s not found in module %s", draftTag, config.Name)
+			}
+
+			if slices.Contains(module.Tags, tag) || slices.Contains(module.DraftTags, tag) {
+				log.Fatalf("tag %s already exists in module %s", tag, config.Name)
+			}
+
+			pulled, err := client.PullModule(cmd.Context(), &v1.PullModuleRequest{
+				Name: config.Name,
+				Tag:  draftTag,
+			})
+			if err != nil {
+				log.Fatalf("failed to pull draft tag %s: %v", draftTag, err)
+			}
+
+			dependencies, err := client.GetModuleDependencies(cmd.Context(), &v1.GetModuleDependenciesRequest{
+				Name: config.Name,
+				Tag:  draftTag,
+			})
+			if err != nil {
+				log.Fatalf("failed to fetch dependencies of draft tag %s: %v", draftTag, err)
+			}
+
+			log.Printf("promoting draft tag %s of module %s to %s", draftTag, config.Name, tag)
+
+			_, err = client.PushModule(cmd.Context(), &v1.PushModuleRequest{
+				ModuleName:   config.Name,
+				Tag:          tag,
+				Protofiles:   pulled.Protofiles,
+				Dependencies: dependencies.Dependencies,
+			})
+			if err != nil {
+				log.Fatalf("failed to push: %v", err)
+			}
+
+			log.Printf("draft tag %s successfully promoted to %s", draftTag, tag)
+
+			if keepDraft {
+				return
+			}
+
+			_, err = client.DeleteModuleTag(cmd.Context(), &v1.DeleteModuleTagRequest{
+				Name: config.Name,
+				Tag:  draftTag,
+			})
+			if err != nil {
+				log.Fatalf("failed to delete draft tag %s: %v", draftTag, err)
+			}
+
+			log.Printf("draft tag %s deleted", draftTag)
+		},
+	}
+
+	promoteModuleCmd.PersistentFlags().Bool("keep-draft", false, "keep the draft tag after the promotion")
+
+	return promoteModuleCmd
+}
+
 func NewDeleteTagCmd(config *model.Config, client v1.RegistryClient) *cobra.Command {
 	// create delete tag command
 	deleteTagCmd := &cobra.Command{
-		Use:   "delete-tag [tag]",
+		Use:   "delete-tag [module_name] [tag]",
 		Short: "Delete tag",
 		Long:  "Delete tag is a command to delete tags",
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.RangeArgs(1, 2),
 		Run: func(cmd *cobra.Command, args []string) {
-			if config.Name == "" {
-				log.Fatalf("module name is required. see pbuf.yaml reference")
+			if len(args) == 1 {
+				if config.Name == "" {
+					log.Fatalf("module name is required. see pbuf.yaml reference")
+				}
+				args = append([]string{config.Name}, args...)
 			}
 
-			tag := args[0]
+			moduleName, tag := args[0], args[1]
 
 			if tag == "" {
 				log.Fatalf("tag is required")
 			}
 
-			_, err := client.DeleteModuleTag(cmd.Context(), &v1.DeleteModuleTagRequest{
-				Name: config.Name,
+			err := confirmDeletion(cmd, client, moduleName, []string{tag}, tag)
+			if err != nil {
+				log.Fatalf("%v", err)
+			}
+
+			_, err = client.DeleteModuleTag(cmd.Context(), &v1.DeleteModuleTagRequest{
+				Name: moduleName,
 				Tag:  tag,
 			})
 
@@ -266,33 +456,56 @@ func NewDeleteTagCmd(config *model.Config, client v1.RegistryClient) *cobra.Comm
 		},
 	}
 
+	deleteTagCmd.Flags().BoolP("yes", "y", false, "skip the confirmation")
+
 	return deleteTagCmd
 }
 
 func NewDeleteModuleCmd(config *model.Config, client v1.RegistryClient) *cobra.Command {
 	// create delete module command
 	deleteModuleCmd := &cobra.Command{
-		Use:   "delete",
+		Use:   "delete [module_name]",
 		Short: "Delete",
 		Long:  "Delete is a command to delete modules",
-		Args:  cobra.ExactArgs(0),
+		Args:  cobra.RangeArgs(0, 1),
 		Run: func(cmd *cobra.Command, args []string) {
-			if config.Name == "" {
-				log.Fatalf("module name is required. see pbuf.yaml reference")
+			if len(args) == 0 {
+				if config.Name == "" {
+					log.Fatalf("module name is required. see pbuf.yaml reference")
+				}
+				args = append(args, config.Name)
 			}
 
-			_, err := client.DeleteModule(cmd.Context(), &v1.DeleteModuleRequest{
-				Name: config.Name,
+			moduleName := args[0]
+
+			module, err := client.GetModule(cmd.Context(), &v1.GetModuleRequest{
+				Name:             moduleName,
+				IncludeDraftTags: true,
+			})
+			if err != nil {
+				log.Fatalf("failed to get module: %v", err)
+			}
+
+			tags := append(append([]string{}, module.Tags...), module.DraftTags...)
+			err = confirmDeletion(cmd, client, moduleName, tags, "")
+			if err != nil {
+				log.Fatalf("%v", err)
+			}
+
+			_, err = client.DeleteModule(cmd.Context(), &v1.DeleteModuleRequest{
+				Name: moduleName,
 			})
 
 			if err != nil {
 				log.Fatalf("failed to delete: %v", err)
 			}
 
-			log.Printf("module %s successfully deleted", config.Name)
+			log.Printf("module %s successfully deleted", moduleName)
 		},
 	}
 
+	deleteModuleCmd.Flags().BoolP("yes", "y", false, "skip the confirmation")
+
 	return deleteModuleCmd
 }
 
@@ -314,8 +527,14 @@ func NewGetModuleCmd(config *model.Config, client v1.RegistryClient) *cobra.Comm
 
 			moduleName := args[0]
 
+			includeDrafts, err := cmd.Flags().GetBool("include-drafts")
+			if err != nil {
+				log.Fatalf("failed to get include-drafts flag: %v", err)
+			}
+
 			module, err := client.GetModule(cmd.Context(), &v1.GetModuleRequest{
-				Name: moduleName,
+				Name:             moduleName,
+				IncludeDraftTags: includeDrafts,
 			})
 
 			if err != nil {
@@ -330,22 +549,39 @@ func NewGetModuleCmd(config *model.Config, client v1.RegistryClient) *cobra.Comm
 				log.Fatalf("failed to fetch dependencies: %v", err)
 			}
 
-			// print as json pretty
-			marshalled, err := json.MarshalIndent(module, "", "  ")
+			renderer, err := newRenderer(cmd)
 			if err != nil {
-				log.Fatalf("failed to marshal module: %v", err)
+				log.Fatalf("%v", err)
 			}
-			log.Printf("module:\n%+v\n", string(marshalled))
 
-			// print deps as json pretty
-			marshalled, err = json.MarshalIndent(moduleDependencies, "", "  ")
+			if !renderer.IsTable() {
+				err = renderer.Render(map[string]any{
+					"module":       module,
+					"dependencies": moduleDependencies.Dependencies,
+				}, nil)
+				if err != nil {
+					log.Fatalf("failed to render module: %v", err)
+				}
+				return
+			}
+
+			err = renderer.RenderTable(modulesTable(module))
 			if err != nil {
-				log.Fatalf("failed to marshal module dependencies: %v", err)
+				log.Fatalf("failed to render module: %v", err)
+			}
+
+			if len(moduleDependencies.Dependencies) > 0 {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout())
+				err = renderer.RenderTable(dependenciesTable(moduleDependencies.Dependencies))
+				if err != nil {
+					log.Fatalf("failed to render module dependencies: %v", err)
+				}
 			}
-			log.Printf("module dependencies:\n%+v\n", string(marshalled))
 		},
 	}
 
+	getModuleCmd.Flags().Bool("include-drafts", false, "include draft tags")
+
 	return getModuleCmd
 }
 
@@ -355,23 +591,84 @@ func NewListModulesCmd(config *model.Config, client v1.RegistryClient) *cobra.Co
 	listModulesCmd := &cobra.Command{
 		Use:   "list",
 		Short: "List",
-		Long:  "List is a command to list modules",
+		Long:  "List is a command to list modules. All pages are listed unless --limit is set",
 		Args:  cobra.ExactArgs(0),
 		Run: func(cmd *cobra.Command, args []string) {
-			modulesList, err := client.ListModules(cmd.Context(), &v1.ListModulesRequest{})
+			filter, err := cmd.Flags().GetString("filter")
 			if err != nil {
-				log.Fatalf("failed to fetch modules list: %v", err)
+				log.Fatalf("failed to get filter flag: %v", err)
+			}
+
+			limit, err := cmd.Flags().GetInt32("limit")
+			if err != nil {
+				log.Fatalf("failed to get limit flag: %v", err)
+			}
+
+			pageToken, err := cmd.Flags().GetString("page-token")
+			if err != nil {
+				log.Fatalf("failed to get page-token flag: %v", err)
 			}
 
-			// print as json pretty
-			marshalled, err := json.MarshalIndent(modulesList, "", "  ")
+			// with limit, a single page is fetched to get the exact next page token
+			pageSize := int32(registry.ListModulesPageSize)
+			if limit > 0 {
+				pageSize = limit
+			}
+
+			renderer, err := newRenderer(cmd)
 			if err != nil {
-				log.Fatalf("failed to marshal modules list: %v", err)
+				log.Fatalf("%v", err)
+			}
+
+			var listed []*v1.Module
+			header := true
+			nextPageToken, err := registry.WalkModules(cmd.Context(), client, pageSize, pageToken, func(modules []*v1.Module) (bool, error) {
+				var page []*v1.Module
+				for _, module := range modules {
+					if filter == "" || registry.MatchModuleName(filter, module.Name) {
+						page = append(page, module)
+					}
+				}
+
+				// tables are streamed page by page, other formats are rendered at once
+				if !renderer.IsTable() {
+					listed = append(listed, page...)
+					return limit <= 0, nil
+				}
+
+				table := modulesTable(page...)
+				table.NoHeader = !header
+				header = false
+				if err := renderer.RenderTable(table); err != nil {
+					return false, err
+				}
+
+				return limit <= 0, nil
+			})
+			if err != nil {
+				log.Fatalf("failed to fetch modules list: %v", err)
+			}
+
+			if !renderer.IsTable() {
+				if listed == nil {
+					listed = []*v1.Module{}
+				}
+				err = renderer.Render(listed, nil)
+				if err != nil {
+					log.Fatalf("failed to render modules list: %v", err)
+				}
+			}
+
+			if nextPageToken != "" {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "next page token: %s\n", nextPageToken)
 			}
-			log.Printf("%+v", string(marshalled))
 		},
 	}
 
+	listModulesCmd.Flags().String("filter", "", "filter modules by name prefix or glob, e.g. acme/ or acme/*-api")
+	listModulesCmd.Flags().Int32("limit", 0, "fetch a single page with at most this number of modules and print the next page token to stderr")
+	listModulesCmd.Flags().String("page-token", "", "page token to start listing from")
+
 	return listModulesCmd
 }
 
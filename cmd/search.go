@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"log"
+	"time"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/model"
+	"github.com/pbufio/pbuf-cli/internal/output"
+	"github.com/pbufio/pbuf-cli/internal/search"
+	"github.com/spf13/cobra"
+)
+
+// NewSearchCmd creates cobra command for search
+func NewSearchCmd(config *model.Config, registryClient v1.RegistryClient, metadataClient v1.MetadataServiceClient) *cobra.Command {
+	searchCmd := &cobra.Command{
+		Use:   "search [query]",
+		Short: "Search",
+		Long: "Search is a command to find messages, fields, enums, services and RPCs by name " +
+			"across the latest tags of all registry modules",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			regex, err := cmd.Flags().GetBool("regex")
+			if err != nil {
+				return err
+			}
+
+			kind, err := cmd.Flags().GetString("kind")
+			if err != nil {
+				return err
+			}
+
+			refresh, err := cmd.Flags().GetBool("refresh")
+			if err != nil {
+				return err
+			}
+
+			maxAge, err := cmd.Flags().GetDuration("max-age")
+			if err != nil {
+				return err
+			}
+
+			query, err := search.NewQuery(args[0], regex, kind)
+			if err != nil {
+				return err
+			}
+
+			cachePath, err := search.CachePath(config.Registry.Addr)
+			if err != nil {
+				return err
+			}
+
+			index, err := search.Load(cachePath)
+			if err != nil {
+				// a broken cache is rebuilt from scratch
+				log.Printf("ignoring search index cache: %v", err)
+				index = nil
+			}
+
+			if refresh || !index.Fresh(maxAge) {
+				log.Printf("indexing registry modules...")
+
+				index, err = search.Build(cmd.Context(), registryClient, metadataClient, index)
+				if err != nil {
+					return err
+				}
+
+				if err := index.Save(cachePath); err != nil {
+					log.Printf("failed to save search index cache: %v", err)
+				}
+			}
+
+			symbols := query.Find(index)
+			if symbols == nil {
+				symbols = []*search.Symbol{}
+			}
+
+			return render(cmd, symbols, symbolsTable(symbols))
+		},
+	}
+
+	searchCmd.Flags().Bool("regex", false, "match the query as a regular expression against the full symbol path")
+	searchCmd.Flags().String("kind", "", "search only symbols of the kind: message, field, enum, service or rpc")
+	searchCmd.Flags().Bool("refresh", false, "rebuild the search index cache before searching")
+	searchCmd.Flags().Duration("max-age", time.Hour, "rebuild the search index cache if it is older than this")
+
+	return searchCmd
+}
+
+func symbolsTable(symbols []*search.Symbol) *output.Table {
+	table := &output.Table{
+		Columns: []output.Column{
+			{Name: "MODULE"},
+			{Name: "PACKAGE"},
+			{Name: "FILE"},
+			{Name: "KIND"},
+			{Name: "SYMBOL"},
+		},
+	}
+
+	for _, symbol := range symbols {
+		table.Rows = append(table.Rows, []string{
+			symbol.Module + "@" + symbol.Tag,
+			symbol.Package,
+			symbol.File,
+			symbol.Kind,
+			symbol.Path,
+		})
+	}
+
+	return table
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/breaking"
+	"github.com/pbufio/pbuf-cli/internal/git"
+	"github.com/pbufio/pbuf-cli/internal/model"
+	"github.com/pbufio/pbuf-cli/internal/version"
+	"github.com/spf13/cobra"
+)
+
+// resolvePushTag returns the tag to push: the argument,
+// the next version of the latest module tag (--bump), the git tag (--from-git)
+// or the current branch name (--draft-from-branch)
+func resolvePushTag(
+	cmd *cobra.Command,
+	config *model.Config,
+	client v1.RegistryClient,
+	args []string,
+	protoFiles []*v1.ProtoFile,
+	ruleSet breaking.RuleSet,
+	skipValidation bool,
+) (string, error) {
+	bump, err := cmd.Flags().GetString("bump")
+	if err != nil {
+		return "", err
+	}
+
+	fromGit, err := cmd.Flags().GetBool("from-git")
+	if err != nil {
+		return "", err
+	}
+
+	draftFromBranch, err := cmd.Flags().GetBool("draft-from-branch")
+	if err != nil {
+		return "", err
+	}
+
+	sources := 0
+	for _, set := range []bool{len(args) > 0, bump != "", fromGit, draftFromBranch} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return "", fmt.Errorf("specify exactly one of the tag argument, --bump, --from-git or --draft-from-branch")
+	}
+
+	switch {
+	case len(args) > 0:
+		return args[0], nil
+	case draftFromBranch:
+		branch, err := git.CurrentBranch(".")
+		if err != nil {
+			return "", err
+		}
+
+		tag := git.DraftTag(branch)
+		if tag == "" {
+			return "", fmt.Errorf("cannot derive a tag from branch %s", branch)
+		}
+		log.Printf("derived draft tag %s from branch %s", tag, branch)
+		return tag, nil
+	case fromGit:
+		tag, err := version.FromGit(cmd.Context(), ".")
+		if err != nil {
+			return "", err
+		}
+		log.Printf("derived tag %s from git", tag)
+		return tag, nil
+	}
+
+	part, err := version.ParseBump(bump)
+	if err != nil {
+		return "", err
+	}
+
+	module, err := client.GetModule(cmd.Context(), &v1.GetModuleRequest{
+		Name: config.Name,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get module %s: %w", config.Name, err)
+	}
+
+	latest := version.Latest(module.Tags)
+
+	suggested := version.BumpPatch
+	if latest != "" {
+		changes, err := findBreakingChanges(cmd.Context(), config, client, protoFiles, latest, ruleSet)
+		if err != nil {
+			return "", err
+		}
+
+		suggested = version.Suggest(latest, len(changes) > 0)
+		if len(changes) > 0 {
+			log.Printf("%d breaking changes found against %s (rules: %s). suggested bump: %s",
+				len(changes), latest, ruleSet, suggested)
+		}
+	}
+
+	if part == version.BumpAuto {
+		part = suggested
+	} else if version.Less(part, suggested) {
+		if !skipValidation {
+			return "", fmt.Errorf("%s bump is not enough for the breaking changes. use --bump %s or --skip-validation", part, suggested)
+		}
+		log.Printf("%s bump is not enough for the breaking changes. bumping anyway", part)
+	}
+
+	tag, err := version.Next(latest, part)
+	if err != nil {
+		return "", err
+	}
+
+	if latest == "" {
+		log.Printf("module %s has no semver tags. derived tag %s", config.Name, tag)
+	} else {
+		log.Printf("derived tag %s from %s (%s bump)", tag, latest, part)
+	}
+
+	return tag, nil
+}
@@ -1,14 +1,14 @@
 package cmd
 
 import (
-	"encoding/json"
 	"errors"
-	"fmt"
 	"log"
-	"strings"
+	"os"
 
 	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/access"
 	"github.com/pbufio/pbuf-cli/internal/model"
+	"github.com/pbufio/pbuf-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -31,23 +31,26 @@ func NewUsersCmd(_ *model.Config, client v1.UserServiceClient) *cobra.Command {
 	usersCmd.AddCommand(newGrantPermissionCmd(client))
 	usersCmd.AddCommand(newRevokePermissionCmd(client))
 	usersCmd.AddCommand(newListUserPermissionsCmd(client))
+	usersCmd.AddCommand(newApplyUsersCmd(client))
+	usersCmd.AddCommand(newExportUsersCmd(client))
 
 	return usersCmd
 }
 
 func newCreateUserCmd(client v1.UserServiceClient) *cobra.Command {
 	createCmd := &cobra.Command{
-		Use:   "create [name]",
-		Short: "Create",
-		Long:  "Create is a command to create a user or bot",
-		Args:  cobra.ExactArgs(1),
+		Use:         "create [name]",
+		Short:       "Create",
+		Long:        "Create is a command to create a user or bot",
+		Args:        cobra.ExactArgs(1),
+		Annotations: jsonByDefault(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			name := args[0]
 			userTypeStr, err := cmd.Flags().GetString("type")
 			if err != nil {
 				return err
 			}
-			userType, err := parseUserType(userTypeStr)
+			userType, err := access.ParseUserType(userTypeStr)
 			if err != nil {
 				return err
 			}
@@ -60,7 +63,11 @@ func newCreateUserCmd(client v1.UserServiceClient) *cobra.Command {
 				return err
 			}
 
-			return printJSON(resp)
+			table := usersTable(resp.User)
+			table.Columns = append(table.Columns, output.Column{Name: "TOKEN"})
+			table.Rows[0] = append(table.Rows[0], resp.Token)
+
+			return render(cmd, resp, table)
 		},
 	}
 
@@ -70,10 +77,11 @@ func newCreateUserCmd(client v1.UserServiceClient) *cobra.Command {
 
 func newListUsersCmd(client v1.UserServiceClient) *cobra.Command {
 	listCmd := &cobra.Command{
-		Use:   "list",
-		Short: "List",
-		Long:  "List is a command to list users and bots",
-		Args:  cobra.ExactArgs(0),
+		Use:         "list",
+		Short:       "List",
+		Long:        "List is a command to list users and bots",
+		Args:        cobra.ExactArgs(0),
+		Annotations: jsonByDefault(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			pageSize, err := cmd.Flags().GetInt32("page-size")
 			if err != nil {
@@ -92,7 +100,7 @@ func newListUsersCmd(client v1.UserServiceClient) *cobra.Command {
 				return err
 			}
 
-			return printJSON(resp)
+			return render(cmd, resp, usersTable(resp.Users...))
 		},
 	}
 
@@ -103,17 +111,18 @@ func newListUsersCmd(client v1.UserServiceClient) *cobra.Command {
 
 func newGetUserCmd(client v1.UserServiceClient) *cobra.Command {
 	getCmd := &cobra.Command{
-		Use:   "get [id]",
-		Short: "Get",
-		Long:  "Get is a command to get a user or bot by id",
-		Args:  cobra.ExactArgs(1),
+		Use:         "get [id]",
+		Short:       "Get",
+		Long:        "Get is a command to get a user or bot by id",
+		Args:        cobra.ExactArgs(1),
+		Annotations: jsonByDefault(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			id := args[0]
 			user, err := client.GetUser(cmd.Context(), &v1.GetUserRequest{Id: id})
 			if err != nil {
 				return err
 			}
-			return printJSON(user)
+			return render(cmd, user, usersTable(user))
 		},
 	}
 
@@ -122,10 +131,11 @@ func newGetUserCmd(client v1.UserServiceClient) *cobra.Command {
 
 func newUpdateUserCmd(client v1.UserServiceClient) *cobra.Command {
 	updateCmd := &cobra.Command{
-		Use:   "update [id]",
-		Short: "Update",
-		Long:  "Update is a command to update a user or bot",
-		Args:  cobra.ExactArgs(1),
+		Use:         "update [id]",
+		Short:       "Update",
+		Long:        "Update is a command to update a user or bot",
+		Args:        cobra.ExactArgs(1),
+		Annotations: jsonByDefault(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			id := args[0]
 			name, err := cmd.Flags().GetString("name")
@@ -171,7 +181,7 @@ func newUpdateUserCmd(client v1.UserServiceClient) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return printJSON(updated)
+			return render(cmd, updated, usersTable(updated))
 		},
 	}
 
@@ -183,17 +193,18 @@ func newUpdateUserCmd(client v1.UserServiceClient) *cobra.Command {
 
 func newDeleteUserCmd(client v1.UserServiceClient) *cobra.Command {
 	deleteCmd := &cobra.Command{
-		Use:   "delete [id]",
-		Short: "Delete",
-		Long:  "Delete is a command to delete a user or bot",
-		Args:  cobra.ExactArgs(1),
+		Use:         "delete [id]",
+		Short:       "Delete",
+		Long:        "Delete is a command to delete a user or bot",
+		Args:        cobra.ExactArgs(1),
+		Annotations: jsonByDefault(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			id := args[0]
 			resp, err := client.DeleteUser(cmd.Context(), &v1.DeleteUserRequest{Id: id})
 			if err != nil {
 				return err
 			}
-			return printJSON(resp)
+			return render(cmd, resp, valueTable("DELETED", resp.Id))
 		},
 	}
 
@@ -202,17 +213,18 @@ func newDeleteUserCmd(client v1.UserServiceClient) *cobra.Command {
 
 func newRegenerateTokenCmd(client v1.UserServiceClient) *cobra.Command {
 	regenCmd := &cobra.Command{
-		Use:   "regenerate-token [id]",
-		Short: "Regenerate token",
-		Long:  "Regenerate token is a command to regenerate a user or bot token",
-		Args:  cobra.ExactArgs(1),
+		Use:         "regenerate-token [id]",
+		Short:       "Regenerate token",
+		Long:        "Regenerate token is a command to regenerate a user or bot token",
+		Args:        cobra.ExactArgs(1),
+		Annotations: jsonByDefault(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			id := args[0]
 			resp, err := client.RegenerateToken(cmd.Context(), &v1.RegenerateTokenRequest{Id: id})
 			if err != nil {
 				return err
 			}
-			return printJSON(resp)
+			return render(cmd, resp, valueTable("TOKEN", resp.Token))
 		},
 	}
 
@@ -221,10 +233,11 @@ func newRegenerateTokenCmd(client v1.UserServiceClient) *cobra.Command {
 
 func newGrantPermissionCmd(client v1.UserServiceClient) *cobra.Command {
 	grantCmd := &cobra.Command{
-		Use:   "grant-permission [user_id] [module_name]",
-		Short: "Grant permission",
-		Long:  "Grant permission is a command to grant permission to a user or bot",
-		Args:  cobra.ExactArgs(2),
+		Use:         "grant-permission [user_id] [module_name]",
+		Short:       "Grant permission",
+		Long:        "Grant permission is a command to grant permission to a user or bot",
+		Args:        cobra.ExactArgs(2),
+		Annotations: jsonByDefault(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			userID := args[0]
 			moduleName := args[1]
@@ -232,7 +245,7 @@ func newGrantPermissionCmd(client v1.UserServiceClient) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			permission, err := parsePermission(permissionStr)
+			permission, err := access.ParsePermission(permissionStr)
 			if err != nil {
 				return err
 			}
@@ -245,7 +258,7 @@ func newGrantPermissionCmd(client v1.UserServiceClient) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return printJSON(resp)
+			return render(cmd, resp, permissionsTable(resp.Entry))
 		},
 	}
 
@@ -256,10 +269,11 @@ func newGrantPermissionCmd(client v1.UserServiceClient) *cobra.Command {
 
 func newRevokePermissionCmd(client v1.UserServiceClient) *cobra.Command {
 	revokeCmd := &cobra.Command{
-		Use:   "revoke-permission [user_id] [module_name]",
-		Short: "Revoke permission",
-		Long:  "Revoke permission is a command to revoke permission from a user or bot",
-		Args:  cobra.ExactArgs(2),
+		Use:         "revoke-permission [user_id] [module_name]",
+		Short:       "Revoke permission",
+		Long:        "Revoke permission is a command to revoke permission from a user or bot",
+		Args:        cobra.ExactArgs(2),
+		Annotations: jsonByDefault(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			userID := args[0]
 			moduleName := args[1]
@@ -270,7 +284,7 @@ func newRevokePermissionCmd(client v1.UserServiceClient) *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return printJSON(resp)
+			return render(cmd, resp, valueTable("REVOKED", resp.Success))
 		},
 	}
 
@@ -279,54 +293,135 @@ func newRevokePermissionCmd(client v1.UserServiceClient) *cobra.Command {
 
 func newListUserPermissionsCmd(client v1.UserServiceClient) *cobra.Command {
 	listCmd := &cobra.Command{
-		Use:   "list-permissions [user_id]",
-		Short: "List permissions",
-		Long:  "List permissions is a command to list permissions for a user or bot",
-		Args:  cobra.ExactArgs(1),
+		Use:         "list-permissions [user_id]",
+		Short:       "List permissions",
+		Long:        "List permissions is a command to list permissions for a user or bot",
+		Args:        cobra.ExactArgs(1),
+		Annotations: jsonByDefault(),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			userID := args[0]
 			resp, err := client.ListUserPermissions(cmd.Context(), &v1.ListUserPermissionsRequest{UserId: userID})
 			if err != nil {
 				return err
 			}
-			return printJSON(resp)
+			return render(cmd, resp, permissionsTable(resp.Permissions...))
 		},
 	}
 
 	return listCmd
 }
 
-func parseUserType(s string) (v1.UserType, error) {
-	s = strings.TrimSpace(strings.ToLower(s))
-	switch s {
-	case "", "user":
-		return v1.UserType_USER_TYPE_USER, nil
-	case "bot":
-		return v1.UserType_USER_TYPE_BOT, nil
-	default:
-		return v1.UserType_USER_TYPE_UNSPECIFIED, fmt.Errorf("unknown user type %q (expected user|bot)", s)
-	}
-}
+func newApplyUsersCmd(client v1.UserServiceClient) *cobra.Command {
+	applyCmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Apply",
+		Long: "Apply is a command to bring the users, bots and permissions of the registry to the state " +
+			"described in the access file. It shows the planned changes before applying them",
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filename, err := cmd.Flags().GetString("file")
+			if err != nil {
+				return err
+			}
+			prune, err := cmd.Flags().GetBool("prune")
+			if err != nil {
+				return err
+			}
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				return err
+			}
+			yes, err := cmd.Flags().GetBool("yes")
+			if err != nil {
+				return err
+			}
+
+			desired, err := access.Load(filename)
+			if err != nil {
+				return err
+			}
+
+			accounts, err := access.Current(cmd.Context(), client)
+			if err != nil {
+				return err
+			}
+
+			steps, err := access.NewPlan(desired, accounts, prune)
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				return render(cmd, accessPlanValue(steps), accessPlanTable(steps, false))
+			}
+
+			if len(steps) == 0 {
+				log.Printf("no changes, the registry users match %s", filename)
+				return render(cmd, accessPlanValue(steps), accessPlanTable(steps, true))
+			}
 
-func parsePermission(s string) (v1.Permission, error) {
-	s = strings.TrimSpace(strings.ToLower(s))
-	switch s {
-	case "read":
-		return v1.Permission_PERMISSION_READ, nil
-	case "write":
-		return v1.Permission_PERMISSION_WRITE, nil
-	case "admin":
-		return v1.Permission_PERMISSION_ADMIN, nil
-	default:
-		return v1.Permission_PERMISSION_UNSPECIFIED, fmt.Errorf("unknown permission %q (expected read|write|admin)", s)
+			log.Printf("the following %d changes will be applied:", len(steps))
+			preview, err := output.NewRenderer(cmd.ErrOrStderr(), string(output.FormatTable), "")
+			if err != nil {
+				return err
+			}
+			if err := preview.RenderTable(accessPlanTable(steps, false)); err != nil {
+				return err
+			}
+
+			if !yes {
+				if err := confirm(cmd, "apply?", "apply aborted"); err != nil {
+					return err
+				}
+			}
+
+			if err := access.Apply(cmd.Context(), client, steps); err != nil {
+				return err
+			}
+
+			return render(cmd, accessPlanValue(steps), accessPlanTable(steps, true))
+		},
 	}
+
+	applyCmd.Flags().StringP("file", "f", "access.yaml", "access file")
+	applyCmd.Flags().Bool("prune", false, "deactivate the users not in the file and revoke their permissions")
+	applyCmd.Flags().Bool("dry-run", false, "print the planned changes without applying them")
+	applyCmd.Flags().BoolP("yes", "y", false, "apply without confirmation")
+	return applyCmd
 }
 
-func printJSON(v any) error {
-	marshalled, err := json.MarshalIndent(v, "", "  ")
-	if err != nil {
-		return err
+func newExportUsersCmd(client v1.UserServiceClient) *cobra.Command {
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export",
+		Long:  "Export is a command to write the current users, bots and permissions of the registry as an access file",
+		Args:  cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filename, err := cmd.Flags().GetString("file")
+			if err != nil {
+				return err
+			}
+
+			accounts, err := access.Current(cmd.Context(), client)
+			if err != nil {
+				return err
+			}
+
+			file := access.Export(accounts)
+			if filename == "" {
+				return file.Write(cmd.OutOrStdout())
+			}
+
+			out, err := os.Create(filename)
+			if err != nil {
+				return err
+			}
+			defer out.Close()
+
+			return file.Write(out)
+		},
 	}
-	log.Printf("%+v", string(marshalled))
-	return nil
+
+	exportCmd.Flags().StringP("file", "f", "", "access file to write (stdout when omitted)")
+	return exportCmd
 }
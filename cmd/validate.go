@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/breaking"
+	"github.com/pbufio/pbuf-cli/internal/model"
+	"github.com/pbufio/pbuf-cli/internal/registry"
+	"github.com/pbufio/pbuf-cli/internal/schema"
+	"github.com/pbufio/pbuf-cli/internal/validate"
+)
+
+// validatePush checks the proto files before pushing them:
+// every file must parse, every import must be satisfied
+// by the exported files or by a registry dependency
+// and the files must compile against them.
+// If the rule set is not empty, the files are also compared with the latest module tag.
+// It returns the problems found
+func validatePush(
+	ctx context.Context,
+	config *model.Config,
+	client v1.RegistryClient,
+	protoFiles []*v1.ProtoFile,
+	ruleSet breaking.RuleSet,
+) ([]string, error) {
+	var problems []string
+
+	var files []*schema.File
+	for _, protoFile := range protoFiles {
+		file, err := schema.Parse(protoFile)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		files = append(files, file)
+	}
+
+	// imports cannot be checked reliably with a partial file set
+	if len(problems) > 0 {
+		return problems, nil
+	}
+
+	var registrySets, gitSets []validate.ImportSet
+	for _, module := range config.Modules {
+		if module.Name != "" && module.Repository == "" {
+			pulled, err := client.PullModule(ctx, &v1.PullModuleRequest{
+				Name: module.Name,
+				Tag:  module.Tag,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to pull dependency %s@%s: %w", module.Name, module.Tag, err)
+			}
+
+			registrySets = append(registrySets, validate.ImportSet{
+				Name:  module.Name,
+				Files: pulled.Protofiles,
+			})
+		} else if module.Repository != "" {
+			dir := module.VendorDir()
+			if _, err := os.Stat(dir); err != nil {
+				continue
+			}
+
+			vendored, err := registry.CollectProtoFilesInDirs([]string{dir})
+			if err != nil {
+				return nil, fmt.Errorf("failed to collect vendored files of %s: %w", module.Repository, err)
+			}
+
+			set := validate.ImportSet{Name: module.Repository}
+			for _, protoFile := range vendored {
+				set.Files = append(set.Files, &v1.ProtoFile{
+					Filename: module.ImportPath(protoFile.Filename),
+					Content:  protoFile.Content,
+				})
+			}
+			gitSets = append(gitSets, set)
+		}
+	}
+
+	for _, problem := range validate.UnresolvedImports(files, registrySets) {
+		location := problem.Import
+		// git modules are not pushed as dependencies,
+		// so consumers have to vendor them on their own
+		if repository, ok := validate.Resolve(location, gitSets); ok {
+			log.Printf("warning: %s:%d: import %q is vendored from %s which consumers have to vendor themselves",
+				problem.Filename, problem.Line, location, repository)
+			continue
+		}
+		problems = append(problems, problem.String())
+	}
+
+	// the compiler would report the unresolved imports once again
+	if len(problems) > 0 {
+		return problems, nil
+	}
+
+	compileProblems, err := validate.Compile(ctx, protoFiles, append(registrySets, gitSets...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile proto files: %w", err)
+	}
+
+	for _, problem := range compileProblems {
+		problems = append(problems, problem.String())
+	}
+
+	if ruleSet == "" {
+		return problems, nil
+	}
+
+	module, err := client.GetModule(ctx, &v1.GetModuleRequest{
+		Name: config.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get module %s: %w", config.Name, err)
+	}
+
+	if len(module.Tags) == 0 {
+		log.Printf("module %s has no tags. skipping breaking check", config.Name)
+		return problems, nil
+	}
+
+	previousTag := module.Tags[0]
+	changes, err := findBreakingChanges(ctx, config, client, protoFiles, previousTag, ruleSet)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range changes {
+		problems = append(problems, fmt.Sprintf("%s (against %s)", change, previousTag))
+	}
+
+	return problems, nil
+}
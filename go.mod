@@ -3,6 +3,7 @@ module github.com/pbufio/pbuf-cli
 go 1.25
 
 require (
+	github.com/bufbuild/protocompile v0.14.1
 	github.com/charmbracelet/bubbles v0.16.1
 	github.com/charmbracelet/bubbletea v0.24.2
 	github.com/go-git/go-billy/v5 v5.6.0
@@ -0,0 +1,139 @@
+package access
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"gopkg.in/yaml.v3"
+)
+
+// File is the desired access control state, e.g. `access.yaml`:
+//
+//	users:
+//	  - name: ci
+//	    type: bot
+//	    permissions:
+//	      acme/payments: write
+//	      "*": read
+type File struct {
+	Users []*User `yaml:"users"`
+}
+
+// User is a user or bot with its permissions by module name ("*" for all modules).
+// Users are matched with the registry ones by name. Active defaults to true
+type User struct {
+	Name        string            `yaml:"name"`
+	Type        string            `yaml:"type,omitempty"`
+	Active      *bool             `yaml:"active,omitempty"`
+	Permissions map[string]string `yaml:"permissions,omitempty"`
+}
+
+// IsActive returns whether the user should be active
+func (u *User) IsActive() bool {
+	return u.Active == nil || *u.Active
+}
+
+// Load reads and validates the access file
+func Load(path string) (*File, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	file := &File{}
+	if err := yaml.Unmarshal(content, file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if err := file.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", path, err)
+	}
+
+	return file, nil
+}
+
+// Validate checks the names are unique, and the types and the permissions are known
+func (f *File) Validate() error {
+	names := map[string]bool{}
+	for _, user := range f.Users {
+		if user.Name == "" {
+			return fmt.Errorf("user without name")
+		}
+		if names[user.Name] {
+			return fmt.Errorf("duplicate user %s", user.Name)
+		}
+		names[user.Name] = true
+
+		if _, err := ParseUserType(user.Type); err != nil {
+			return fmt.Errorf("user %s: %w", user.Name, err)
+		}
+
+		for module, permission := range user.Permissions {
+			if module == "" {
+				return fmt.Errorf("user %s: permission without module name", user.Name)
+			}
+			if _, err := ParsePermission(permission); err != nil {
+				return fmt.Errorf("user %s: module %s: %w", user.Name, module, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Write encodes the access file as YAML with the users sorted by name
+func (f *File) Write(w io.Writer) error {
+	sort.Slice(f.Users, func(i, j int) bool {
+		return f.Users[i].Name < f.Users[j].Name
+	})
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(f); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// ParseUserType parses the user type name: user or bot
+func ParseUserType(s string) (v1.UserType, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	switch s {
+	case "", "user":
+		return v1.UserType_USER_TYPE_USER, nil
+	case "bot":
+		return v1.UserType_USER_TYPE_BOT, nil
+	default:
+		return v1.UserType_USER_TYPE_UNSPECIFIED, fmt.Errorf("unknown user type %q (expected user|bot)", s)
+	}
+}
+
+// ParsePermission parses the permission name: read, write or admin
+func ParsePermission(s string) (v1.Permission, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	switch s {
+	case "read":
+		return v1.Permission_PERMISSION_READ, nil
+	case "write":
+		return v1.Permission_PERMISSION_WRITE, nil
+	case "admin":
+		return v1.Permission_PERMISSION_ADMIN, nil
+	default:
+		return v1.Permission_PERMISSION_UNSPECIFIED, fmt.Errorf("unknown permission %q (expected read|write|admin)", s)
+	}
+}
+
+// UserTypeName returns the lower case name of the user type, e.g. `bot`
+func UserTypeName(userType v1.UserType) string {
+	return strings.ToLower(strings.TrimPrefix(userType.String(), "USER_TYPE_"))
+}
+
+// PermissionName returns the lower case name of the permission, e.g. `write`
+func PermissionName(permission v1.Permission) string {
+	return strings.ToLower(strings.TrimPrefix(permission.String(), "PERMISSION_"))
+}
@@ -0,0 +1,190 @@
+package access
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"google.golang.org/grpc"
+	"gopkg.in/yaml.v3"
+)
+
+type fakeUserClient struct {
+	v1.UserServiceClient
+	users       []*v1.User
+	permissions map[string][]*v1.ACLEntry
+	calls       []string
+}
+
+func (f *fakeUserClient) ListUsers(_ context.Context, in *v1.ListUsersRequest, _ ...grpc.CallOption) (*v1.ListUsersResponse, error) {
+	start := int(in.Page * in.PageSize)
+	end := min(start+int(in.PageSize), len(f.users))
+	if start > end {
+		start = end
+	}
+	return &v1.ListUsersResponse{Users: f.users[start:end], Total: int32(len(f.users))}, nil
+}
+
+func (f *fakeUserClient) ListUserPermissions(_ context.Context, in *v1.ListUserPermissionsRequest, _ ...grpc.CallOption) (*v1.ListUserPermissionsResponse, error) {
+	return &v1.ListUserPermissionsResponse{Permissions: f.permissions[in.UserId]}, nil
+}
+
+func (f *fakeUserClient) CreateUser(_ context.Context, in *v1.CreateUserRequest, _ ...grpc.CallOption) (*v1.CreateUserResponse, error) {
+	f.calls = append(f.calls, fmt.Sprintf("create %s %s", in.Name, UserTypeName(in.Type)))
+	return &v1.CreateUserResponse{User: &v1.User{Id: "new-" + in.Name, Name: in.Name}, Token: "secret"}, nil
+}
+
+func (f *fakeUserClient) UpdateUser(_ context.Context, in *v1.UpdateUserRequest, _ ...grpc.CallOption) (*v1.User, error) {
+	f.calls = append(f.calls, fmt.Sprintf("update %s active=%t", in.Id, in.IsActive))
+	return &v1.User{Id: in.Id, IsActive: in.IsActive}, nil
+}
+
+func (f *fakeUserClient) GrantPermission(_ context.Context, in *v1.GrantPermissionRequest, _ ...grpc.CallOption) (*v1.GrantPermissionResponse, error) {
+	f.calls = append(f.calls, fmt.Sprintf("grant %s %s %s", in.UserId, in.ModuleName, PermissionName(in.Permission)))
+	return &v1.GrantPermissionResponse{}, nil
+}
+
+func (f *fakeUserClient) RevokePermission(_ context.Context, in *v1.RevokePermissionRequest, _ ...grpc.CallOption) (*v1.RevokePermissionResponse, error) {
+	f.calls = append(f.calls, fmt.Sprintf("revoke %s %s", in.UserId, in.ModuleName))
+	return &v1.RevokePermissionResponse{Success: true}, nil
+}
+
+func newFakeUserClient() *fakeUserClient {
+	return &fakeUserClient{
+		users: []*v1.User{
+			{Id: "1", Name: "alice", Type: v1.UserType_USER_TYPE_USER, IsActive: true},
+			{Id: "2", Name: "deploy", Type: v1.UserType_USER_TYPE_BOT, IsActive: true},
+			{Id: "3", Name: "bob", Type: v1.UserType_USER_TYPE_USER, IsActive: true},
+		},
+		permissions: map[string][]*v1.ACLEntry{
+			"1": {{UserId: "1", ModuleName: "*", Permission: v1.Permission_PERMISSION_ADMIN}},
+			"2": {
+				{UserId: "2", ModuleName: "acme/payments", Permission: v1.Permission_PERMISSION_READ},
+				{UserId: "2", ModuleName: "acme/orders", Permission: v1.Permission_PERMISSION_WRITE},
+			},
+			"3": {{UserId: "3", ModuleName: "acme/orders", Permission: v1.Permission_PERMISSION_READ}},
+		},
+	}
+}
+
+func TestApply(t *testing.T) {
+	inactive := false
+	desired := &File{Users: []*User{
+		{Name: "alice", Permissions: map[string]string{"*": "admin"}},
+		{Name: "deploy", Type: "bot", Permissions: map[string]string{"acme/payments": "write"}},
+		{Name: "ci", Type: "bot", Active: &inactive, Permissions: map[string]string{"acme/payments": "read"}},
+	}}
+	if err := desired.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		prune bool
+		want  []string
+	}{
+		{
+			name: "unlisted users unchanged",
+			want: []string{
+				"grant 2 acme/payments write",
+				"revoke 2 acme/orders",
+				"create ci bot",
+				"update new-ci active=false",
+				"grant new-ci acme/payments read",
+			},
+		},
+		{
+			name:  "prune",
+			prune: true,
+			want: []string{
+				"grant 2 acme/payments write",
+				"revoke 2 acme/orders",
+				"create ci bot",
+				"update new-ci active=false",
+				"grant new-ci acme/payments read",
+				"update 3 active=false",
+				"revoke 3 acme/orders",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeUserClient()
+
+			accounts, err := Current(context.Background(), client)
+			if err != nil {
+				t.Fatalf("Current() error = %v", err)
+			}
+
+			steps, err := NewPlan(desired, accounts, tt.prune)
+			if err != nil {
+				t.Fatalf("NewPlan() error = %v", err)
+			}
+			if got := steps[0].Describe(); got != "read -> write" {
+				t.Errorf("Describe() got = %v, want read -> write", got)
+			}
+
+			if err := Apply(context.Background(), client, steps); err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if !reflect.DeepEqual(client.calls, tt.want) {
+				t.Errorf("Apply() got = %v, want %v", client.calls, tt.want)
+			}
+			if steps[2].Token != "secret" {
+				t.Errorf("Apply() got token = %v, want secret", steps[2].Token)
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	client := newFakeUserClient()
+
+	accounts, err := Current(context.Background(), client)
+	if err != nil {
+		t.Fatalf("Current() error = %v", err)
+	}
+
+	var buffer bytes.Buffer
+	if err := Export(accounts).Write(&buffer); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	exported := &File{}
+	if err := yaml.Unmarshal(buffer.Bytes(), exported); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(exported.Users) != 3 || exported.Users[0].Name != "alice" || exported.Users[1].Permissions["*"] != "" {
+		t.Errorf("Export() got = %s", buffer.String())
+	}
+
+	// the exported state has nothing to apply
+	steps, err := NewPlan(exported, accounts, true)
+	if err != nil {
+		t.Fatalf("NewPlan() error = %v", err)
+	}
+	if len(steps) != 0 {
+		t.Errorf("NewPlan() got = %v, want no steps", steps)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		file *File
+	}{
+		{name: "duplicate", file: &File{Users: []*User{{Name: "a"}, {Name: "a"}}}},
+		{name: "type", file: &File{Users: []*User{{Name: "a", Type: "robot"}}}},
+		{name: "permission", file: &File{Users: []*User{{Name: "a", Permissions: map[string]string{"*": "owner"}}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.file.Validate(); err == nil {
+				t.Errorf("Validate() expected error")
+			}
+		})
+	}
+}
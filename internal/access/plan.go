@@ -0,0 +1,277 @@
+package access
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+)
+
+const pageSize = 100
+
+// Plan actions
+const (
+	ActionCreate     = "create"
+	ActionActivate   = "activate"
+	ActionDeactivate = "deactivate"
+	ActionGrant      = "grant"
+	ActionRevoke     = "revoke"
+)
+
+// Account is a registry user with its permissions
+type Account struct {
+	User        *v1.User
+	Permissions []*v1.ACLEntry
+}
+
+// Step is a change of the plan. UserID is empty for the users created by the plan.
+// Token is set when a user is created
+type Step struct {
+	Action     string
+	User       string
+	UserID     string
+	Type       v1.UserType
+	Module     string
+	Permission v1.Permission
+	Previous   v1.Permission
+	Token      string
+}
+
+// Describe returns the change of the step, e.g. `read -> write`
+func (s *Step) Describe() string {
+	switch s.Action {
+	case ActionCreate:
+		return UserTypeName(s.Type)
+	case ActionGrant:
+		if s.Previous != v1.Permission_PERMISSION_UNSPECIFIED {
+			return PermissionName(s.Previous) + " -> " + PermissionName(s.Permission)
+		}
+		return PermissionName(s.Permission)
+	case ActionRevoke:
+		return PermissionName(s.Previous)
+	default:
+		return ""
+	}
+}
+
+// Current lists the registry users with their permissions
+func Current(ctx context.Context, client v1.UserServiceClient) ([]*Account, error) {
+	var accounts []*Account
+	for page := int32(0); ; page++ {
+		resp, err := client.ListUsers(ctx, &v1.ListUsersRequest{PageSize: pageSize, Page: page})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list users: %w", err)
+		}
+
+		for _, user := range resp.GetUsers() {
+			permissions, err := client.ListUserPermissions(ctx, &v1.ListUserPermissionsRequest{UserId: user.GetId()})
+			if err != nil {
+				return nil, fmt.Errorf("failed to list permissions of %s: %w", user.GetName(), err)
+			}
+			accounts = append(accounts, &Account{User: user, Permissions: permissions.GetPermissions()})
+		}
+
+		if len(resp.GetUsers()) < pageSize || len(accounts) >= int(resp.GetTotal()) {
+			return accounts, nil
+		}
+	}
+}
+
+// Export converts the registry users to the access file
+func Export(accounts []*Account) *File {
+	file := &File{Users: []*User{}}
+	for _, account := range accounts {
+		active := account.User.GetIsActive()
+		user := &User{
+			Name:   account.User.GetName(),
+			Type:   UserTypeName(account.User.GetType()),
+			Active: &active,
+		}
+
+		for _, entry := range account.Permissions {
+			if user.Permissions == nil {
+				user.Permissions = map[string]string{}
+			}
+			user.Permissions[entry.GetModuleName()] = PermissionName(entry.GetPermission())
+		}
+
+		file.Users = append(file.Users, user)
+	}
+
+	return file
+}
+
+// NewPlan diffs the desired users with the registry ones. The registry users not in the file
+// are left unchanged unless prune is set: then they are deactivated and their permissions revoked
+func NewPlan(desired *File, accounts []*Account, prune bool) ([]*Step, error) {
+	current := map[string]*Account{}
+	for _, account := range accounts {
+		name := account.User.GetName()
+		if _, ok := current[name]; ok {
+			return nil, fmt.Errorf("several registry users are named %s, they cannot be matched by name", name)
+		}
+		current[name] = account
+	}
+
+	var steps []*Step
+	listed := map[string]bool{}
+	for _, user := range desired.Users {
+		listed[user.Name] = true
+
+		userType, err := ParseUserType(user.Type)
+		if err != nil {
+			return nil, fmt.Errorf("user %s: %w", user.Name, err)
+		}
+
+		account, ok := current[user.Name]
+		if !ok {
+			steps = append(steps, &Step{Action: ActionCreate, User: user.Name, Type: userType})
+			if !user.IsActive() {
+				steps = append(steps, &Step{Action: ActionDeactivate, User: user.Name})
+			}
+			account = &Account{User: &v1.User{Name: user.Name, IsActive: true}}
+		} else if account.User.GetType() != userType {
+			return nil, fmt.Errorf("user %s is a %s in the registry, the type cannot be changed",
+				user.Name, UserTypeName(account.User.GetType()))
+		}
+
+		userSteps, err := diffUser(account, user)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, userSteps...)
+	}
+
+	if prune {
+		for _, account := range accounts {
+			if listed[account.User.GetName()] {
+				continue
+			}
+
+			userSteps, err := diffUser(account, &User{Name: account.User.GetName(), Active: new(bool)})
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, userSteps...)
+		}
+	}
+
+	return steps, nil
+}
+
+// diffUser returns the activation and the permission changes of the registry user
+func diffUser(account *Account, user *User) ([]*Step, error) {
+	var steps []*Step
+	id := account.User.GetId()
+
+	if id != "" && account.User.GetIsActive() != user.IsActive() {
+		action := ActionDeactivate
+		if user.IsActive() {
+			action = ActionActivate
+		}
+		steps = append(steps, &Step{Action: action, User: user.Name, UserID: id})
+	}
+
+	granted := map[string]v1.Permission{}
+	for _, entry := range account.Permissions {
+		granted[entry.GetModuleName()] = entry.GetPermission()
+	}
+
+	modules := make([]string, 0, len(user.Permissions))
+	for module := range user.Permissions {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+
+	for _, module := range modules {
+		permission, err := ParsePermission(user.Permissions[module])
+		if err != nil {
+			return nil, fmt.Errorf("user %s: module %s: %w", user.Name, module, err)
+		}
+
+		previous := granted[module]
+		if previous == permission {
+			continue
+		}
+
+		steps = append(steps, &Step{
+			Action:     ActionGrant,
+			User:       user.Name,
+			UserID:     id,
+			Module:     module,
+			Permission: permission,
+			Previous:   previous,
+		})
+	}
+
+	revoked := make([]string, 0, len(granted))
+	for module := range granted {
+		if _, ok := user.Permissions[module]; !ok {
+			revoked = append(revoked, module)
+		}
+	}
+	sort.Strings(revoked)
+
+	for _, module := range revoked {
+		steps = append(steps, &Step{
+			Action:   ActionRevoke,
+			User:     user.Name,
+			UserID:   id,
+			Module:   module,
+			Previous: granted[module],
+		})
+	}
+
+	return steps, nil
+}
+
+// Apply runs the steps in order. The users created by the plan get their ids and tokens
+// set in the following steps. It stops at the first failed step
+func Apply(ctx context.Context, client v1.UserServiceClient, steps []*Step) error {
+	created := map[string]string{}
+	for _, step := range steps {
+		if step.UserID == "" {
+			step.UserID = created[step.User]
+		}
+
+		var err error
+		switch step.Action {
+		case ActionCreate:
+			var resp *v1.CreateUserResponse
+			resp, err = client.CreateUser(ctx, &v1.CreateUserRequest{Name: step.User, Type: step.Type})
+			if err == nil {
+				step.UserID = resp.GetUser().GetId()
+				step.Token = resp.GetToken()
+				created[step.User] = step.UserID
+			}
+		case ActionActivate, ActionDeactivate:
+			_, err = client.UpdateUser(ctx, &v1.UpdateUserRequest{
+				Id:       step.UserID,
+				IsActive: step.Action == ActionActivate,
+			})
+		case ActionGrant:
+			_, err = client.GrantPermission(ctx, &v1.GrantPermissionRequest{
+				UserId:     step.UserID,
+				ModuleName: step.Module,
+				Permission: step.Permission,
+			})
+		case ActionRevoke:
+			_, err = client.RevokePermission(ctx, &v1.RevokePermissionRequest{
+				UserId:     step.UserID,
+				ModuleName: step.Module,
+			})
+		default:
+			err = fmt.Errorf("unknown action")
+		}
+
+		if err != nil {
+			if step.Module != "" {
+				return fmt.Errorf("failed to %s %s on %s: %w", step.Action, step.User, step.Module, err)
+			}
+			return fmt.Errorf("failed to %s %s: %w", step.Action, step.User, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,438 @@
+package breaking
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pbufio/pbuf-cli/internal/schema"
+)
+
+// RuleSet selects which changes are reported as breaking
+type RuleSet string
+
+const (
+	// RuleSetWire reports changes that break the binary encoding
+	RuleSetWire RuleSet = "wire"
+	// RuleSetSource reports changes that break the generated code
+	// It includes all wire checks
+	RuleSetSource RuleSet = "source"
+)
+
+// ParseRuleSet parses the rule set name
+func ParseRuleSet(s string) (RuleSet, error) {
+	switch RuleSet(strings.ToLower(strings.TrimSpace(s))) {
+	case "", RuleSetSource:
+		return RuleSetSource, nil
+	case RuleSetWire:
+		return RuleSetWire, nil
+	default:
+		return "", fmt.Errorf("unknown rule set %q (expected wire|source)", s)
+	}
+}
+
+const (
+	RulePackageNoChange     = "PACKAGE_NO_CHANGE"
+	RuleFileNoDelete        = "FILE_NO_DELETE"
+	RuleMessageNoDelete     = "MESSAGE_NO_DELETE"
+	RuleFieldNoDelete       = "FIELD_NO_DELETE"
+	RuleFieldSameNumber     = "FIELD_SAME_NUMBER"
+	RuleFieldSameName       = "FIELD_SAME_NAME"
+	RuleFieldSameType       = "FIELD_SAME_TYPE"
+	RuleFieldSameLabel      = "FIELD_SAME_LABEL"
+	RuleFieldSameOneof      = "FIELD_SAME_ONEOF"
+	RuleEnumNoDelete        = "ENUM_NO_DELETE"
+	RuleEnumValueNoDelete   = "ENUM_VALUE_NO_DELETE"
+	RuleEnumValueSameNumber = "ENUM_VALUE_SAME_NUMBER"
+	RuleEnumValueSameName   = "ENUM_VALUE_SAME_NAME"
+	RuleServiceNoDelete     = "SERVICE_NO_DELETE"
+	RuleRPCNoDelete         = "RPC_NO_DELETE"
+	RuleRPCSameTypes        = "RPC_SAME_TYPES"
+	RuleRPCSameStreaming    = "RPC_SAME_STREAMING"
+)
+
+// Change is a breaking change found in the current files
+type Change struct {
+	Rule     string `json:"rule"`
+	Filename string `json:"filename"`
+	Line     int    `json:"line"`
+	Message  string `json:"message"`
+}
+
+func (c *Change) String() string {
+	return fmt.Sprintf("%s:%d: %s %s", c.Filename, c.Line, c.Rule, c.Message)
+}
+
+type comparator struct {
+	ruleSet  RuleSet
+	previous *schema.Index
+	current  *schema.Index
+	// renamed maps previous packages to the current ones
+	renamed map[string]string
+	// currentFiles maps types to the files they are declared in
+	currentFiles map[string]string
+	changes      []*Change
+}
+
+// Compare finds breaking changes between the previous and the current files
+func Compare(previous, current []*schema.File, ruleSet RuleSet) []*Change {
+	c := &comparator{
+		ruleSet:      ruleSet,
+		previous:     schema.NewIndex(previous),
+		current:      schema.NewIndex(current),
+		renamed:      map[string]string{},
+		currentFiles: map[string]string{},
+	}
+
+	for _, file := range current {
+		for _, name := range declaredTypes(file) {
+			c.currentFiles[name] = file.Name
+		}
+	}
+
+	c.compareFiles(previous, current)
+
+	for _, name := range sortedKeys(c.previous.Messages) {
+		c.compareMessage(c.previous.Messages[name])
+	}
+
+	for _, name := range sortedKeys(c.previous.Enums) {
+		c.compareEnum(c.previous.Enums[name])
+	}
+
+	for _, name := range sortedKeys(c.previous.Services) {
+		c.compareService(c.previous.Services[name])
+	}
+
+	sort.SliceStable(c.changes, func(i, j int) bool {
+		if c.changes[i].Filename != c.changes[j].Filename {
+			return c.changes[i].Filename < c.changes[j].Filename
+		}
+		return c.changes[i].Line < c.changes[j].Line
+	})
+
+	return c.changes
+}
+
+func (c *comparator) report(rule, filename string, line int, format string, args ...any) {
+	c.changes = append(c.changes, &Change{
+		Rule:     rule,
+		Filename: filename,
+		Line:     line,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *comparator) isSource() bool {
+	return c.ruleSet == RuleSetSource
+}
+
+func (c *comparator) compareFiles(previous, current []*schema.File) {
+	currentByName := map[string]*schema.File{}
+	for _, file := range current {
+		currentByName[file.Name] = file
+	}
+
+	for _, previousFile := range previous {
+		currentFile, ok := currentByName[previousFile.Name]
+		if !ok {
+			if c.isSource() {
+				c.report(RuleFileNoDelete, previousFile.Name, 0, "file %s was deleted", previousFile.Name)
+			}
+			continue
+		}
+
+		if previousFile.Package != currentFile.Package {
+			c.renamed[previousFile.Package] = currentFile.Package
+			c.report(RulePackageNoChange, currentFile.Name, 1,
+				"package changed from %q to %q", previousFile.Package, currentFile.Package)
+		}
+	}
+}
+
+// currentName maps the previous full name to the current one
+// taking the package renames into account
+func (c *comparator) currentName(fullName string) string {
+	for previousPackage, currentPackage := range c.renamed {
+		if previousPackage != "" && strings.HasPrefix(fullName, previousPackage+".") {
+			return currentPackage + strings.TrimPrefix(fullName, previousPackage)
+		}
+	}
+	return fullName
+}
+
+func (c *comparator) compareMessage(previous *schema.Message) {
+	current, ok := c.current.Messages[c.currentName(previous.FullName)]
+	if !ok {
+		if c.isSource() {
+			c.report(RuleMessageNoDelete, c.fileOf(previous.FullName), 0, "message %s was deleted", previous.FullName)
+		}
+		return
+	}
+
+	filename := c.currentFiles[topLevel(c.current, current.FullName)]
+
+	currentByNumber := map[int]*schema.Field{}
+	currentByName := map[string]*schema.Field{}
+	for _, field := range current.Fields {
+		currentByNumber[field.Number] = field
+		currentByName[field.Name] = field
+	}
+
+	for _, previousField := range previous.Fields {
+		renumbered, moved := currentByName[previousField.Name]
+		moved = moved && renumbered.Number != previousField.Number
+		if moved {
+			c.report(RuleFieldSameNumber, filename, renumbered.Line,
+				"field %s.%s changed number from %d to %d",
+				current.FullName, previousField.Name, previousField.Number, renumbered.Number)
+		}
+
+		currentField, ok := currentByNumber[previousField.Number]
+		if !ok {
+			if moved {
+				continue
+			}
+			if c.isSource() || !current.Reserved.HasNumber(previousField.Number) {
+				c.report(RuleFieldNoDelete, filename, current.Line,
+					"field %d (%s) was deleted from %s", previousField.Number, previousField.Name, current.FullName)
+			}
+			continue
+		}
+
+		c.compareField(filename, current.FullName, previousField, currentField)
+	}
+}
+
+func (c *comparator) compareField(filename, messageName string, previous, current *schema.Field) {
+	if c.isSource() && previous.Name != current.Name {
+		c.report(RuleFieldSameName, filename, current.Line,
+			"field %d on %s changed name from %q to %q", current.Number, messageName, previous.Name, current.Name)
+	}
+
+	if c.labelOf(previous) != c.labelOf(current) {
+		c.report(RuleFieldSameLabel, filename, current.Line,
+			"field %s.%s changed label from %q to %q", messageName, current.Name, label(previous), label(current))
+		return
+	}
+
+	if previous.Oneof != current.Oneof {
+		c.report(RuleFieldSameOneof, filename, current.Line,
+			"field %s.%s changed oneof from %q to %q", messageName, current.Name, previous.Oneof, current.Oneof)
+	}
+
+	previousType := c.currentName(previous.Type)
+	previousKey := previous.KeyType
+
+	if c.isSource() {
+		if previousType != current.Type || previousKey != current.KeyType {
+			c.report(RuleFieldSameType, filename, current.Line,
+				"field %s.%s changed type from %q to %q", messageName, current.Name, typeString(previous), typeString(current))
+		}
+		return
+	}
+
+	if !c.wireCompatible(previous.Type, current.Type) || !c.wireCompatible(previousKey, current.KeyType) {
+		c.report(RuleFieldSameType, filename, current.Line,
+			"field %s.%s changed type from %q to %q which is not wire compatible",
+			messageName, current.Name, typeString(previous), typeString(current))
+	}
+}
+
+func (c *comparator) compareEnum(previous *schema.Enum) {
+	current, ok := c.current.Enums[c.currentName(previous.FullName)]
+	if !ok {
+		if c.isSource() {
+			c.report(RuleEnumNoDelete, c.fileOf(previous.FullName), 0, "enum %s was deleted", previous.FullName)
+		}
+		return
+	}
+
+	filename := c.currentFiles[topLevel(c.current, current.FullName)]
+
+	currentByNumber := map[int]*schema.EnumValue{}
+	currentByName := map[string]*schema.EnumValue{}
+	for _, value := range current.Values {
+		if _, ok := currentByNumber[value.Number]; !ok {
+			currentByNumber[value.Number] = value
+		}
+		currentByName[value.Name] = value
+	}
+
+	for _, previousValue := range previous.Values {
+		currentValue, ok := currentByNumber[previousValue.Number]
+		if !ok {
+			if c.isSource() || !current.Reserved.HasNumber(previousValue.Number) {
+				c.report(RuleEnumValueNoDelete, filename, current.Line,
+					"enum value %d (%s) was deleted from %s", previousValue.Number, previousValue.Name, current.FullName)
+			}
+			continue
+		}
+
+		if !c.isSource() {
+			continue
+		}
+
+		if renumbered, ok := currentByName[previousValue.Name]; ok && renumbered.Number != previousValue.Number {
+			c.report(RuleEnumValueSameNumber, filename, renumbered.Line,
+				"enum value %s.%s changed number from %d to %d",
+				current.FullName, previousValue.Name, previousValue.Number, renumbered.Number)
+		} else if currentValue.Name != previousValue.Name {
+			c.report(RuleEnumValueSameName, filename, currentValue.Line,
+				"enum value %d on %s changed name from %q to %q",
+				previousValue.Number, current.FullName, previousValue.Name, currentValue.Name)
+		}
+	}
+}
+
+func (c *comparator) compareService(previous *schema.Service) {
+	current, ok := c.current.Services[c.currentName(previous.FullName)]
+	if !ok {
+		c.report(RuleServiceNoDelete, c.fileOf(previous.FullName), 0, "service %s was deleted", previous.FullName)
+		return
+	}
+
+	filename := c.currentFiles[current.FullName]
+
+	currentByName := map[string]*schema.Method{}
+	for _, method := range current.Methods {
+		currentByName[method.Name] = method
+	}
+
+	for _, previousMethod := range previous.Methods {
+		currentMethod, ok := currentByName[previousMethod.Name]
+		if !ok {
+			c.report(RuleRPCNoDelete, filename, current.Line,
+				"rpc %s was deleted from %s", previousMethod.Name, current.FullName)
+			continue
+		}
+
+		if c.currentName(previousMethod.InputType) != currentMethod.InputType ||
+			c.currentName(previousMethod.OutputType) != currentMethod.OutputType {
+			c.report(RuleRPCSameTypes, filename, currentMethod.Line,
+				"rpc %s.%s changed types from (%s) returns (%s) to (%s) returns (%s)",
+				current.FullName, currentMethod.Name,
+				previousMethod.InputType, previousMethod.OutputType,
+				currentMethod.InputType, currentMethod.OutputType)
+		}
+
+		if previousMethod.ClientStreaming != currentMethod.ClientStreaming ||
+			previousMethod.ServerStreaming != currentMethod.ServerStreaming {
+			c.report(RuleRPCSameStreaming, filename, currentMethod.Line,
+				"rpc %s.%s changed streaming", current.FullName, currentMethod.Name)
+		}
+	}
+}
+
+// fileOf returns the previous file of the deleted type
+func (c *comparator) fileOf(fullName string) string {
+	topLevelName := topLevel(c.previous, fullName)
+	for _, file := range c.previous.Files {
+		for _, name := range declaredTypes(file) {
+			if name == topLevelName {
+				return file.Name
+			}
+		}
+	}
+	return ""
+}
+
+// wireGroups contains the scalar types sharing the same wire encoding
+var wireGroups = map[string]string{
+	"int32": "varint", "int64": "varint", "uint32": "varint", "uint64": "varint", "bool": "varint",
+	"sint32": "zigzag", "sint64": "zigzag",
+	"fixed32": "fixed32", "sfixed32": "fixed32",
+	"fixed64": "fixed64", "sfixed64": "fixed64",
+	"string": "bytes", "bytes": "bytes",
+	"float": "float", "double": "double",
+}
+
+func (c *comparator) wireCompatible(previous, current string) bool {
+	previousGroup := c.wireGroup(c.previous, previous)
+	currentGroup := c.wireGroup(c.current, current)
+	if previousGroup != "" || currentGroup != "" {
+		return previousGroup == currentGroup
+	}
+
+	return c.currentName(previous) == current
+}
+
+func (c *comparator) wireGroup(index *schema.Index, typeName string) string {
+	if group, ok := wireGroups[typeName]; ok {
+		return group
+	}
+	if index.Kind(typeName) == schema.KindEnum {
+		return "varint"
+	}
+	return ""
+}
+
+// labelOf returns the field label. proto3 optional fields
+// have the same encoding as the singular ones
+func (c *comparator) labelOf(field *schema.Field) string {
+	if !c.isSource() && field.Optional {
+		return ""
+	}
+	return label(field)
+}
+
+func label(field *schema.Field) string {
+	switch {
+	case field.Map:
+		return "map"
+	case field.Repeated:
+		return "repeated"
+	case field.Required:
+		return "required"
+	case field.Optional:
+		return "optional"
+	default:
+		return ""
+	}
+}
+
+func typeString(field *schema.Field) string {
+	if field.Map {
+		return fmt.Sprintf("map<%s, %s>", field.KeyType, field.Type)
+	}
+	return field.Type
+}
+
+// declaredTypes returns the top level types declared in the file
+func declaredTypes(file *schema.File) []string {
+	var result []string
+	for _, message := range file.Messages {
+		result = append(result, message.FullName)
+	}
+	for _, enum := range file.Enums {
+		result = append(result, enum.FullName)
+	}
+	for _, service := range file.Services {
+		result = append(result, service.FullName)
+	}
+	return result
+}
+
+// topLevel returns the top level message containing the nested type
+func topLevel(index *schema.Index, fullName string) string {
+	for {
+		dot := strings.LastIndex(fullName, ".")
+		if dot < 0 {
+			return fullName
+		}
+		parent := fullName[:dot]
+		if _, ok := index.Messages[parent]; !ok {
+			return fullName
+		}
+		fullName = parent
+	}
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,200 @@
+package breaking
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/schema"
+)
+
+const previousProtoFile = `
+syntax = "proto3";
+package acme.v1;
+
+message Money {
+  string currency = 1;
+  int64 units = 2;
+  int32 nanos = 3;
+  reserved 10;
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_ACTIVE = 1;
+  STATUS_DELETED = 2;
+}
+
+service Payments {
+  rpc Pay(Money) returns (Money);
+  rpc Refund(Money) returns (Money);
+}
+`
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		ruleSet RuleSet
+		want    []string
+	}{
+		{
+			name:    "no changes",
+			current: previousProtoFile,
+			ruleSet: RuleSetSource,
+			want:    nil,
+		},
+		{
+			name: "removed field, rpc and enum value",
+			current: `
+syntax = "proto3";
+package acme.v1;
+
+message Money {
+  string currency = 1;
+  int64 units = 2;
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_ACTIVE = 1;
+}
+
+service Payments {
+  rpc Pay(Money) returns (Money);
+}
+`,
+			ruleSet: RuleSetWire,
+			want:    []string{RuleFieldNoDelete, RuleEnumValueNoDelete, RuleRPCNoDelete},
+		},
+		{
+			name: "reserved field is wire compatible only",
+			current: `
+syntax = "proto3";
+package acme.v1;
+
+message Money {
+  string currency = 1;
+  int64 units = 2;
+  reserved 3, 10;
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_ACTIVE = 1;
+  STATUS_DELETED = 2;
+}
+
+service Payments {
+  rpc Pay(Money) returns (Money);
+  rpc Refund(Money) returns (Money);
+}
+`,
+			ruleSet: RuleSetWire,
+			want:    nil,
+		},
+		{
+			name: "changed field number and type",
+			current: `
+syntax = "proto3";
+package acme.v1;
+
+message Money {
+  string currency = 1;
+  uint64 units = 2;
+  string nanos = 4;
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_ACTIVE = 1;
+  STATUS_REMOVED = 2;
+}
+
+service Payments {
+  rpc Pay(Money) returns (Money);
+  rpc Refund(Money) returns (Money);
+}
+`,
+			ruleSet: RuleSetSource,
+			want:    []string{RuleFieldSameType, RuleFieldSameNumber, RuleEnumValueSameName},
+		},
+		{
+			name: "wire compatible type change",
+			current: `
+syntax = "proto3";
+package acme.v1;
+
+message Money {
+  string currency = 1;
+  uint64 units = 2;
+  sint32 nanos = 3;
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_ACTIVE = 1;
+  STATUS_REMOVED = 2;
+}
+
+service Payments {
+  rpc Pay(Money) returns (Money);
+  rpc Refund(Money) returns (Money);
+}
+`,
+			ruleSet: RuleSetWire,
+			want:    []string{RuleFieldSameType},
+		},
+		{
+			name: "package rename",
+			current: `
+syntax = "proto3";
+package acme.v2;
+
+message Money {
+  string currency = 1;
+  int64 units = 2;
+  int32 nanos = 3;
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_ACTIVE = 1;
+  STATUS_DELETED = 2;
+}
+
+service Payments {
+  rpc Pay(Money) returns (Money);
+  rpc Refund(Money) returns (Money);
+}
+`,
+			ruleSet: RuleSetSource,
+			want:    []string{RulePackageNoChange},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := mustParse(t, previousProtoFile)
+			current := mustParse(t, tt.current)
+
+			var got []string
+			for _, change := range Compare(previous, current, tt.ruleSet) {
+				got = append(got, change.Rule)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compare() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func mustParse(t *testing.T, content string) []*schema.File {
+	t.Helper()
+
+	files, err := schema.ParseAll([]*v1.ProtoFile{{Filename: "acme/v1/money.proto", Content: content}})
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	return files
+}
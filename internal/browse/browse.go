@@ -0,0 +1,315 @@
+package browse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+)
+
+const help = "↑/↓ move • enter open • esc back • / filter • a add to pbuf.yaml • q quit"
+
+// AddFunc adds the module tag to pbuf.yaml
+type AddFunc func(module, tag string) error
+
+// Model is the registry browser: modules, their tags and drafts,
+// dependencies, files and parsed metadata down to the fields
+type Model struct {
+	ctx       context.Context
+	source    *source
+	add       AddFunc
+	stack     []*screen
+	height    int
+	status    string
+	loading   bool
+	filtering bool
+}
+
+type openedMsg struct {
+	screen *screen
+}
+
+type failedMsg struct {
+	err error
+}
+
+type addedMsg struct {
+	status string
+}
+
+// New creates the registry browser starting from the modules list
+func New(
+	ctx context.Context,
+	registryClient v1.RegistryClient,
+	metadataClient v1.MetadataServiceClient,
+	add AddFunc,
+) *Model {
+	return &Model{
+		ctx:     ctx,
+		source:  &source{registry: registryClient, metadata: metadataClient},
+		add:     add,
+		loading: true,
+	}
+}
+
+func (m *Model) Init() tea.Cmd {
+	return m.load(m.source.modulesScreen)
+}
+
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case openedMsg:
+		m.loading = false
+		m.status = ""
+		m.stack = append(m.stack, msg.screen)
+	case failedMsg:
+		m.loading = false
+		m.status = "error: " + msg.err.Error()
+	case addedMsg:
+		m.status = msg.status
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC {
+			return m, tea.Quit
+		}
+		if m.filtering {
+			m.updateFilter(msg)
+			return m, nil
+		}
+		return m, m.handleKey(msg)
+	}
+
+	return m, nil
+}
+
+func (m *Model) handleKey(msg tea.KeyMsg) tea.Cmd {
+	current := m.current()
+
+	if msg.String() == "q" {
+		return tea.Quit
+	}
+
+	// the screen being loaded is opened on top of the current one
+	if current == nil || m.loading {
+		return nil
+	}
+
+	switch msg.String() {
+	case "esc", "backspace", "left", "h":
+		if current.filter != "" {
+			current.filter = ""
+			current.cursor, current.offset = 0, 0
+		} else if len(m.stack) > 1 {
+			m.stack = m.stack[:len(m.stack)-1]
+		}
+		m.status = ""
+	case "up", "k":
+		m.move(-1)
+	case "down", "j":
+		m.move(1)
+	case "pgup":
+		m.move(-m.rows())
+	case "pgdown", " ":
+		m.move(m.rows())
+	case "/":
+		if current.lines == nil {
+			m.filtering = true
+		}
+	case "enter", "right", "l":
+		selected := m.selected()
+		if selected != nil && selected.open != nil {
+			m.loading = true
+			m.status = "loading..."
+			return m.load(selected.open)
+		}
+	case "a":
+		selected := m.selected()
+		if selected == nil || selected.module == "" {
+			m.status = "select a module, a tag or an entry of a tag to add"
+			return nil
+		}
+		return m.addModule(selected.module, selected.tag)
+	}
+
+	return nil
+}
+
+func (m *Model) updateFilter(msg tea.KeyMsg) {
+	current := m.current()
+
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.filtering = false
+	case tea.KeyEsc:
+		m.filtering = false
+		current.filter = ""
+	case tea.KeyBackspace:
+		if current.filter != "" {
+			current.filter = current.filter[:len(current.filter)-1]
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		current.filter += string(msg.Runes)
+	}
+
+	current.cursor, current.offset = 0, 0
+}
+
+func (m *Model) load(open func(ctx context.Context) (*screen, error)) tea.Cmd {
+	return func() tea.Msg {
+		opened, err := open(m.ctx)
+		if err != nil {
+			return failedMsg{err: err}
+		}
+		return openedMsg{screen: opened}
+	}
+}
+
+// addModule adds the module tag to pbuf.yaml. A module selected in the modules list
+// is added with its latest tag
+func (m *Model) addModule(module, tag string) tea.Cmd {
+	return func() tea.Msg {
+		if tag == "" {
+			latest, err := m.source.latestTag(m.ctx, module)
+			if err != nil {
+				return failedMsg{err: err}
+			}
+			tag = latest
+		}
+
+		if err := m.add(module, tag); err != nil {
+			return failedMsg{err: err}
+		}
+
+		return addedMsg{status: fmt.Sprintf("added %s@%s to pbuf.yaml", module, tag)}
+	}
+}
+
+func (m *Model) current() *screen {
+	if len(m.stack) == 0 {
+		return nil
+	}
+	return m.stack[len(m.stack)-1]
+}
+
+// visible returns the items of the current screen matching the filter
+func (m *Model) visible() []*item {
+	current := m.current()
+	if current.filter == "" {
+		return current.items
+	}
+
+	filter := strings.ToLower(current.filter)
+	var items []*item
+	for _, it := range current.items {
+		if strings.Contains(strings.ToLower(it.label), filter) {
+			items = append(items, it)
+		}
+	}
+	return items
+}
+
+func (m *Model) selected() *item {
+	current := m.current()
+	if current.lines != nil {
+		return nil
+	}
+
+	items := m.visible()
+	if current.cursor >= len(items) {
+		return nil
+	}
+	return items[current.cursor]
+}
+
+// move moves the cursor of the lists and scrolls the files
+func (m *Model) move(delta int) {
+	current := m.current()
+	rows := m.rows()
+
+	if current.lines != nil {
+		current.offset = clamp(current.offset+delta, 0, len(current.lines)-rows)
+		return
+	}
+
+	current.cursor = clamp(current.cursor+delta, 0, len(m.visible())-1)
+	if current.cursor < current.offset {
+		current.offset = current.cursor
+	}
+	if current.cursor >= current.offset+rows {
+		current.offset = current.cursor - rows + 1
+	}
+}
+
+// rows returns the number of list rows fitting the window
+// below the title and above the status and help lines
+func (m *Model) rows() int {
+	if m.height <= 0 {
+		return 20
+	}
+	return max(m.height-4, 1)
+}
+
+func (m *Model) View() string {
+	var out strings.Builder
+
+	titles := []string{"pbuf browse"}
+	for _, s := range m.stack {
+		titles = append(titles, s.title)
+	}
+	out.WriteString(strings.Join(titles, " › ") + "\n\n")
+
+	if current := m.current(); current != nil {
+		rows := m.rows()
+
+		if current.lines != nil {
+			end := min(current.offset+rows, len(current.lines))
+			for _, line := range current.lines[current.offset:end] {
+				out.WriteString(line + "\n")
+			}
+		} else {
+			items := m.visible()
+			if len(items) == 0 {
+				out.WriteString("  (empty)\n")
+			}
+
+			end := min(current.offset+rows, len(items))
+			for i := current.offset; i < end; i++ {
+				cursor := "  "
+				if i == current.cursor {
+					cursor = "> "
+				}
+				suffix := ""
+				if items[i].open != nil {
+					suffix = " ›"
+				}
+				out.WriteString(cursor + items[i].label + suffix + "\n")
+			}
+		}
+	} else if !m.loading {
+		out.WriteString("  (empty)\n")
+	}
+
+	status := m.status
+	if m.loading && status == "" {
+		status = "loading..."
+	}
+	if current := m.current(); status == "" && current != nil && (m.filtering || current.filter != "") {
+		status = "filter: " + current.filter
+	}
+
+	out.WriteString("\n" + status + "\n" + help)
+	return out.String()
+}
+
+func clamp(value, low, high int) int {
+	if value > high {
+		value = high
+	}
+	if value < low {
+		value = low
+	}
+	return value
+}
@@ -0,0 +1,201 @@
+package browse
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"google.golang.org/grpc"
+)
+
+type fakeRegistryClient struct {
+	v1.RegistryClient
+}
+
+func (c *fakeRegistryClient) ListModules(_ context.Context, _ *v1.ListModulesRequest, _ ...grpc.CallOption) (*v1.ListModulesResponse, error) {
+	return &v1.ListModulesResponse{Modules: []*v1.Module{{Name: "acme/common"}, {Name: "acme/payments"}}}, nil
+}
+
+func (c *fakeRegistryClient) GetModule(_ context.Context, in *v1.GetModuleRequest, _ ...grpc.CallOption) (*v1.Module, error) {
+	return &v1.Module{Name: in.Name, Tags: []string{"v1.1.0", "v1.0.0"}, DraftTags: []string{"feature-x"}}, nil
+}
+
+func (c *fakeRegistryClient) PullModule(_ context.Context, in *v1.PullModuleRequest, _ ...grpc.CallOption) (*v1.PullModuleResponse, error) {
+	return &v1.PullModuleResponse{
+		Protofiles: []*v1.ProtoFile{{Filename: "acme/v1/payments.proto", Content: "syntax = \"proto3\";\n\npackage acme.v1;\n"}},
+	}, nil
+}
+
+func (c *fakeRegistryClient) GetModuleDependencies(_ context.Context, _ *v1.GetModuleDependenciesRequest, _ ...grpc.CallOption) (*v1.GetModuleDependenciesResponse, error) {
+	return &v1.GetModuleDependenciesResponse{Dependencies: []*v1.Dependency{{Name: "acme/common", Tag: "v1.0.0"}}}, nil
+}
+
+type fakeMetadataClient struct {
+	v1.MetadataServiceClient
+}
+
+func (c *fakeMetadataClient) GetMetadata(_ context.Context, _ *v1.GetMetadataRequest, _ ...grpc.CallOption) (*v1.GetMetadataResponse, error) {
+	return &v1.GetMetadataResponse{
+		Packages: []*v1.Package{
+			{
+				Name: "acme.v1",
+				ProtoFiles: []*v1.ParsedProtoFile{
+					{
+						Messages: []*v1.Message{
+							{Name: "PayRequest", Fields: []*v1.Field{{Name: "tags", MessageType: "string", Tag: 5, Repeated: true}}},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// press sends the keys to the model and runs the returned commands
+func press(t *testing.T, m *Model, keys ...string) {
+	t.Helper()
+
+	for _, key := range keys {
+		var msg tea.KeyMsg
+		switch key {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+
+		_, cmd := m.Update(msg)
+		run(m, cmd)
+	}
+}
+
+func run(m *Model, cmd tea.Cmd) {
+	if cmd != nil {
+		m.Update(cmd())
+	}
+}
+
+func TestModel(t *testing.T) {
+	var added []string
+	m := New(context.Background(), &fakeRegistryClient{}, &fakeMetadataClient{}, func(module, tag string) error {
+		added = append(added, module+"@"+tag)
+		return nil
+	})
+	run(m, m.Init())
+
+	tests := []struct {
+		name string
+		keys []string
+		want []string
+	}{
+		{
+			name: "modules",
+			want: []string{"pbuf browse › modules", "> acme/common ›", "  acme/payments ›"},
+		},
+		{
+			name: "filter modules",
+			keys: []string{"/", "p", "a", "y", "enter"},
+			want: []string{"> acme/payments ›", "filter: pay"},
+		},
+		{
+			name: "tags and drafts",
+			keys: []string{"enter"},
+			want: []string{"modules › acme/payments", "> v1.1.0 ›", "  feature-x (draft) ›"},
+		},
+		{
+			name: "tag entries",
+			keys: []string{"enter"},
+			want: []string{
+				"› acme/payments@v1.1.0",
+				"> package acme.v1 ›",
+				"  dependency acme/common@v1.0.0 ›",
+				"  file acme/v1/payments.proto ›",
+			},
+		},
+		{
+			name: "fields",
+			keys: []string{"enter", "enter"},
+			want: []string{"acme.v1 › PayRequest", "> repeated string tags = 5"},
+		},
+		{
+			name: "file content",
+			keys: []string{"esc", "esc", "down", "down", "enter"},
+			want: []string{"› acme/v1/payments.proto", "package acme.v1;"},
+		},
+		{
+			name: "add selected dependency",
+			keys: []string{"esc", "k", "a"},
+			want: []string{"> dependency acme/common@v1.0.0", "added acme/common@v1.0.0 to pbuf.yaml"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			press(t, m, tt.keys...)
+
+			view := m.View()
+			for _, want := range tt.want {
+				if !strings.Contains(view, want) {
+					t.Errorf("View() does not contain %q:\n%s", want, view)
+				}
+			}
+		})
+	}
+
+	if len(added) != 1 || added[0] != "acme/common@v1.0.0" {
+		t.Errorf("added got = %v, want [acme/common@v1.0.0]", added)
+	}
+}
+
+type untaggedRegistryClient struct {
+	fakeRegistryClient
+}
+
+func (c *untaggedRegistryClient) GetModule(_ context.Context, in *v1.GetModuleRequest, _ ...grpc.CallOption) (*v1.Module, error) {
+	return &v1.Module{Name: in.Name}, nil
+}
+
+func TestAddFromModules(t *testing.T) {
+	tests := []struct {
+		name      string
+		client    v1.RegistryClient
+		want      string
+		wantAdded []string
+	}{
+		{
+			name:      "latest tag",
+			client:    &fakeRegistryClient{},
+			want:      "added acme/common@v1.1.0 to pbuf.yaml",
+			wantAdded: []string{"acme/common@v1.1.0"},
+		},
+		{
+			name:   "no tags",
+			client: &untaggedRegistryClient{},
+			want:   "error: module acme/common has no tags to add",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var added []string
+			m := New(context.Background(), tt.client, &fakeMetadataClient{}, func(module, tag string) error {
+				added = append(added, module+"@"+tag)
+				return nil
+			})
+			run(m, m.Init())
+
+			press(t, m, "a")
+
+			if view := m.View(); !strings.Contains(view, tt.want) {
+				t.Errorf("View() does not contain %q:\n%s", tt.want, view)
+			}
+			if strings.Join(added, ",") != strings.Join(tt.wantAdded, ",") {
+				t.Errorf("added got = %v, want %v", added, tt.wantAdded)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package browse
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/metadata"
+	"github.com/pbufio/pbuf-cli/internal/registry"
+)
+
+// screen is a list of items, or the lines of a file when lines is set
+type screen struct {
+	title  string
+	items  []*item
+	lines  []string
+	cursor int
+	offset int
+	filter string
+}
+
+// item is a screen entry. The entries without open are leaves.
+// Module and tag are set for the entries that can be added to pbuf.yaml
+type item struct {
+	label  string
+	module string
+	tag    string
+	open   func(ctx context.Context) (*screen, error)
+}
+
+// source loads the screens from the registry
+type source struct {
+	registry v1.RegistryClient
+	metadata v1.MetadataServiceClient
+}
+
+func (s *source) modulesScreen(ctx context.Context) (*screen, error) {
+	modules, err := registry.ListAllModules(ctx, s.registry)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &screen{title: "modules"}
+	for _, module := range modules {
+		name := module.Name
+		result.items = append(result.items, &item{
+			label:  name,
+			module: name,
+			open: func(ctx context.Context) (*screen, error) {
+				return s.moduleScreen(ctx, name)
+			},
+		})
+	}
+
+	return result, nil
+}
+
+// latestTag returns the latest tag of the module, drafts excluded
+func (s *source) latestTag(ctx context.Context, name string) (string, error) {
+	module, err := s.registry.GetModule(ctx, &v1.GetModuleRequest{Name: name})
+	if err != nil {
+		return "", fmt.Errorf("failed to get module %s: %w", name, err)
+	}
+
+	if len(module.GetTags()) == 0 {
+		return "", fmt.Errorf("module %s has no tags to add", name)
+	}
+
+	return module.GetTags()[0], nil
+}
+
+func (s *source) moduleScreen(ctx context.Context, name string) (*screen, error) {
+	module, err := s.registry.GetModule(ctx, &v1.GetModuleRequest{
+		Name:             name,
+		IncludeDraftTags: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get module %s: %w", name, err)
+	}
+
+	result := &screen{title: name}
+	addTag := func(tag, label string) {
+		result.items = append(result.items, &item{
+			label:  label,
+			module: name,
+			tag:    tag,
+			open: func(ctx context.Context) (*screen, error) {
+				return s.tagScreen(ctx, name, tag)
+			},
+		})
+	}
+
+	for _, tag := range module.Tags {
+		addTag(tag, tag)
+	}
+	for _, tag := range module.DraftTags {
+		addTag(tag, tag+" (draft)")
+	}
+
+	return result, nil
+}
+
+func (s *source) tagScreen(ctx context.Context, name, tag string) (*screen, error) {
+	pulled, err := s.registry.PullModule(ctx, &v1.PullModuleRequest{Name: name, Tag: tag})
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull %s@%s: %w", name, tag, err)
+	}
+
+	dependencies, err := s.registry.GetModuleDependencies(ctx, &v1.GetModuleDependenciesRequest{Name: name, Tag: tag})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dependencies of %s@%s: %w", name, tag, err)
+	}
+
+	meta, err := s.metadata.GetMetadata(ctx, &v1.GetMetadataRequest{Name: name, Tag: tag})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metadata of %s@%s: %w", name, tag, err)
+	}
+
+	result := &screen{title: name + "@" + tag}
+
+	for _, pkg := range meta.Packages {
+		result.items = append(result.items, &item{
+			label:  "package " + pkg.Name,
+			module: name,
+			tag:    tag,
+			open: func(context.Context) (*screen, error) {
+				return packageScreen(pkg), nil
+			},
+		})
+	}
+
+	for _, dependency := range dependencies.Dependencies {
+		result.items = append(result.items, &item{
+			label:  "dependency " + dependency.Name + "@" + dependency.Tag,
+			module: dependency.Name,
+			tag:    dependency.Tag,
+			open: func(ctx context.Context) (*screen, error) {
+				return s.tagScreen(ctx, dependency.Name, dependency.Tag)
+			},
+		})
+	}
+
+	files := append([]*v1.ProtoFile{}, pulled.Protofiles...)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Filename < files[j].Filename
+	})
+	for _, file := range files {
+		result.items = append(result.items, &item{
+			label:  "file " + file.Filename,
+			module: name,
+			tag:    tag,
+			open: func(context.Context) (*screen, error) {
+				return &screen{
+					title: file.Filename,
+					lines: strings.Split(strings.TrimRight(file.Content, "\n"), "\n"),
+				}, nil
+			},
+		})
+	}
+
+	return result, nil
+}
+
+func packageScreen(pkg *v1.Package) *screen {
+	result := &screen{title: pkg.GetName()}
+
+	for _, file := range pkg.GetProtoFiles() {
+		for _, service := range file.GetServices() {
+			result.items = append(result.items, &item{
+				label: "service " + service.GetName(),
+				open: func(context.Context) (*screen, error) {
+					return serviceScreen(service), nil
+				},
+			})
+		}
+
+		for _, message := range file.GetMessages() {
+			result.items = append(result.items, messageItem(message))
+		}
+	}
+
+	return result
+}
+
+func serviceScreen(service *v1.Service) *screen {
+	result := &screen{title: service.GetName()}
+	for _, method := range service.GetMethods() {
+		result.items = append(result.items, &item{
+			label: fmt.Sprintf("rpc %s(%s) returns (%s)", method.GetName(), method.GetInputType(), method.GetOutputType()),
+		})
+	}
+	return result
+}
+
+func messageItem(message *v1.Message) *item {
+	return &item{
+		label: "message " + message.GetName(),
+		open: func(context.Context) (*screen, error) {
+			return messageScreen(message), nil
+		},
+	}
+}
+
+func messageScreen(message *v1.Message) *screen {
+	result := &screen{title: message.GetName()}
+
+	for _, field := range message.GetFields() {
+		if !field.GetOneof() {
+			result.items = append(result.items, &item{label: metadata.Declaration(field)})
+			continue
+		}
+
+		for i, name := range field.GetOneofNames() {
+			typeName := ""
+			if i < len(field.GetOneofTypes()) {
+				typeName = field.GetOneofTypes()[i] + " "
+			}
+			result.items = append(result.items, &item{
+				label: fmt.Sprintf("oneof %s: %s%s", field.GetName(), typeName, name),
+			})
+		}
+	}
+
+	for _, nested := range message.GetNestedMessages() {
+		result.items = append(result.items, messageItem(nested))
+	}
+
+	for _, enum := range message.GetNestedEnums() {
+		result.items = append(result.items, &item{
+			label: "enum " + enum.GetName(),
+			open: func(context.Context) (*screen, error) {
+				values := &screen{title: enum.GetName()}
+				for _, value := range enum.GetValues() {
+					values.items = append(values.items, &item{
+						label: fmt.Sprintf("%s = %d", value.GetName(), value.GetTag()),
+					})
+				}
+				return values, nil
+			},
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,88 @@
+package drift
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/output"
+)
+
+// ModuleDependencies is the dependency drift status of a module tag
+type ModuleDependencies struct {
+	Name     string
+	Tag      string
+	Statuses []*v1.DependencyDriftStatus
+}
+
+// Verdict summarizes the dependency drift of the modules,
+// e.g. `drifting: 2 dependencies to update, 1 to review (highest severity: critical)`.
+// Statuses without a recommendation are not counted
+func Verdict(modules []*ModuleDependencies) string {
+	var updates, reviews int
+	highest := v1.DriftSeverity_DRIFT_SEVERITY_UNSPECIFIED
+
+	for _, module := range modules {
+		for _, status := range module.Statuses {
+			switch status.GetRecommendation() {
+			case v1.DependencyDriftRecommendation_DEPENDENCY_DRIFT_RECOMMENDATION_SUGGEST_UPDATE:
+				updates++
+			case v1.DependencyDriftRecommendation_DEPENDENCY_DRIFT_RECOMMENDATION_ALERT_REVIEW:
+				reviews++
+			default:
+				continue
+			}
+			if status.GetSeverity() > highest {
+				highest = status.GetSeverity()
+			}
+		}
+	}
+
+	if updates+reviews == 0 {
+		return "up to date: no dependency drift"
+	}
+
+	verdict := fmt.Sprintf("drifting: %d dependencies to update, %d to review", updates, reviews)
+	if highest != v1.DriftSeverity_DRIFT_SEVERITY_UNSPECIFIED {
+		verdict += fmt.Sprintf(" (highest severity: %s)", SeverityName(highest))
+	}
+	return verdict
+}
+
+// ProjectReport converts the dependency drift statuses of the modules to findings
+func ProjectReport(modules []*ModuleDependencies) *output.Report {
+	report := &output.Report{Name: "dependency-drift", Rules: dependencyRules}
+	for _, module := range modules {
+		moduleReport := DependenciesReport(module.Name+"@"+module.Tag, module.Statuses)
+		report.Findings = append(report.Findings, moduleReport.Findings...)
+	}
+	return report
+}
+
+// ProjectValue returns the dependency drift statuses of the modules with the verdict
+// in the form rendered by the json, yaml and template formats
+func ProjectValue(modules []*ModuleDependencies) map[string]any {
+	values := make([]map[string]any, 0, len(modules))
+	for _, module := range modules {
+		statuses := module.Statuses
+		if statuses == nil {
+			statuses = []*v1.DependencyDriftStatus{}
+		}
+		values = append(values, map[string]any{
+			"name":     module.Name,
+			"tag":      module.Tag,
+			"statuses": statuses,
+		})
+	}
+
+	return map[string]any{
+		"modules": values,
+		"verdict": Verdict(modules),
+	}
+}
+
+// RecommendationName returns the short lower case name of the recommendation, e.g. `suggest-update`
+func RecommendationName(recommendation v1.DependencyDriftRecommendation) string {
+	name := strings.ToLower(strings.TrimPrefix(recommendation.String(), recommendationPrefix))
+	return strings.ReplaceAll(name, "_", "-")
+}
@@ -0,0 +1,90 @@
+package drift
+
+import (
+	"testing"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+)
+
+func TestVerdict(t *testing.T) {
+	tests := []struct {
+		name    string
+		modules []*ModuleDependencies
+		want    string
+	}{
+		{
+			name:    "no modules",
+			modules: nil,
+			want:    "up to date: no dependency drift",
+		},
+		{
+			name: "no drift",
+			modules: []*ModuleDependencies{
+				{Name: "acme/payments", Tag: "v1.0.0"},
+			},
+			want: "up to date: no dependency drift",
+		},
+		{
+			name: "no recommendation",
+			modules: []*ModuleDependencies{
+				{
+					Name: "acme/payments",
+					Tag:  "v1.0.0",
+					Statuses: []*v1.DependencyDriftStatus{
+						{
+							DependencyName: "acme/common",
+							Severity:       v1.DriftSeverity_DRIFT_SEVERITY_WARNING,
+							Recommendation: v1.DependencyDriftRecommendation_DEPENDENCY_DRIFT_RECOMMENDATION_UNSPECIFIED,
+						},
+					},
+				},
+			},
+			want: "up to date: no dependency drift",
+		},
+		{
+			name: "drifting",
+			modules: []*ModuleDependencies{
+				{
+					Name: "acme/payments",
+					Tag:  "v1.0.0",
+					Statuses: []*v1.DependencyDriftStatus{
+						{
+							DependencyName: "acme/common",
+							Severity:       v1.DriftSeverity_DRIFT_SEVERITY_WARNING,
+							Recommendation: v1.DependencyDriftRecommendation_DEPENDENCY_DRIFT_RECOMMENDATION_SUGGEST_UPDATE,
+						},
+					},
+				},
+				{
+					Name: "acme/orders",
+					Tag:  "v2.1.0",
+					Statuses: []*v1.DependencyDriftStatus{
+						{
+							DependencyName: "acme/common",
+							Severity:       v1.DriftSeverity_DRIFT_SEVERITY_CRITICAL,
+							Recommendation: v1.DependencyDriftRecommendation_DEPENDENCY_DRIFT_RECOMMENDATION_ALERT_REVIEW,
+						},
+						{
+							DependencyName: "acme/money",
+							Severity:       v1.DriftSeverity_DRIFT_SEVERITY_INFO,
+							Recommendation: v1.DependencyDriftRecommendation_DEPENDENCY_DRIFT_RECOMMENDATION_SUGGEST_UPDATE,
+						},
+					},
+				},
+			},
+			want: "drifting: 2 dependencies to update, 1 to review (highest severity: critical)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verdict(tt.modules); got != tt.want {
+				t.Errorf("Verdict() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	report := ProjectReport(tests[3].modules)
+	if len(report.Findings) != 3 || report.Findings[1].Group != "acme/orders@v2.1.0" {
+		t.Errorf("ProjectReport() got = %+v", report.Findings)
+	}
+}
@@ -0,0 +1,99 @@
+package drift
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+)
+
+const severityPrefix = "DRIFT_SEVERITY_"
+
+// Severities are the severity names accepted by ParseSeverity
+var Severities = []string{"info", "warning", "critical"}
+
+// ParseSeverity parses the severity name, e.g. `warning` or `DRIFT_SEVERITY_WARNING`
+func ParseSeverity(name string) (v1.DriftSeverity, error) {
+	value, ok := v1.DriftSeverity_value[severityPrefix+strings.TrimPrefix(strings.ToUpper(name), severityPrefix)]
+	if !ok || value == int32(v1.DriftSeverity_DRIFT_SEVERITY_UNSPECIFIED) {
+		return 0, fmt.Errorf("unknown severity %s, expected one of: %s", name, strings.Join(Severities, ", "))
+	}
+	return v1.DriftSeverity(value), nil
+}
+
+// SeverityName returns the short lower case name of the severity, e.g. `warning`
+func SeverityName(severity v1.DriftSeverity) string {
+	return strings.ToLower(strings.TrimPrefix(severity.String(), severityPrefix))
+}
+
+// ParseAge parses a duration that also accepts days, e.g. `30d` or `12h`
+func ParseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %s", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %s: %w", value, err)
+	}
+	return age, nil
+}
+
+// Filter selects drift events. Empty fields match any event
+type Filter struct {
+	// Module is a module name or a glob, e.g. `acme/*`
+	Module   string
+	Tag      string
+	Severity v1.DriftSeverity
+	// OlderThan selects the events detected at least this long ago
+	OlderThan time.Duration
+}
+
+// Empty reports whether the filter matches any event
+func (f *Filter) Empty() bool {
+	return f.Module == "" && f.Tag == "" && f.Severity == v1.DriftSeverity_DRIFT_SEVERITY_UNSPECIFIED && f.OlderThan == 0
+}
+
+// Match reports whether the event matches the filter at the time
+func (f *Filter) Match(event *v1.DriftEvent, now time.Time) bool {
+	if f.Module != "" {
+		matched, err := path.Match(f.Module, event.GetModuleName())
+		if err != nil || !matched {
+			return false
+		}
+	}
+
+	if f.Tag != "" && event.GetTagName() != f.Tag {
+		return false
+	}
+
+	if f.Severity != v1.DriftSeverity_DRIFT_SEVERITY_UNSPECIFIED && event.GetSeverity() != f.Severity {
+		return false
+	}
+
+	if f.OlderThan > 0 {
+		if event.GetDetectedAt() == nil || now.Sub(event.GetDetectedAt().AsTime()) < f.OlderThan {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Select returns the events matching the filter at the time
+func (f *Filter) Select(events []*v1.DriftEvent, now time.Time) []*v1.DriftEvent {
+	var selected []*v1.DriftEvent
+	for _, event := range events {
+		if f.Match(event, now) {
+			selected = append(selected, event)
+		}
+	}
+	return selected
+}
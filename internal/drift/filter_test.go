@@ -0,0 +1,106 @@
+package drift
+
+import (
+	"testing"
+	"time"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestParseSeverity(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    v1.DriftSeverity
+		wantErr bool
+	}{
+		{name: "info", want: v1.DriftSeverity_DRIFT_SEVERITY_INFO},
+		{name: "Warning", want: v1.DriftSeverity_DRIFT_SEVERITY_WARNING},
+		{name: "DRIFT_SEVERITY_CRITICAL", want: v1.DriftSeverity_DRIFT_SEVERITY_CRITICAL},
+		{name: "unspecified", wantErr: true},
+		{name: "fatal", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSeverity(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSeverity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseSeverity() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "30d", want: 30 * 24 * time.Hour},
+		{value: "12h", want: 12 * time.Hour},
+		{value: "1h30m", want: 90 * time.Minute},
+		{value: "xd", wantErr: true},
+		{value: "week", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseAge(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAge() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	event := &v1.DriftEvent{
+		ModuleName: "acme/payments",
+		TagName:    "v1.0.0",
+		Severity:   v1.DriftSeverity_DRIFT_SEVERITY_WARNING,
+		DetectedAt: timestamppb.New(now.Add(-48 * time.Hour)),
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{name: "empty", filter: Filter{}, want: true},
+		{name: "module", filter: Filter{Module: "acme/payments"}, want: true},
+		{name: "module is not a prefix", filter: Filter{Module: "acme/pay"}, want: false},
+		{name: "module glob", filter: Filter{Module: "acme/*"}, want: true},
+		{name: "tag", filter: Filter{Tag: "v2.0.0"}, want: false},
+		{name: "severity", filter: Filter{Severity: v1.DriftSeverity_DRIFT_SEVERITY_WARNING}, want: true},
+		{name: "other severity", filter: Filter{Severity: v1.DriftSeverity_DRIFT_SEVERITY_CRITICAL}, want: false},
+		{name: "older than", filter: Filter{OlderThan: 24 * time.Hour}, want: true},
+		{name: "newer", filter: Filter{OlderThan: 72 * time.Hour}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(event, now); got != tt.want {
+				t.Errorf("Match() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeen(t *testing.T) {
+	seen := NewSeen()
+
+	first := seen.Add([]*v1.DriftEvent{{Id: "1"}, {Id: "2"}})
+	if len(first) != 2 {
+		t.Errorf("Add() got = %v, want 2 events", first)
+	}
+
+	second := seen.Add([]*v1.DriftEvent{{Id: "2"}, {Id: "3"}, {Id: "3"}})
+	if len(second) != 1 || second[0].Id != "3" {
+		t.Errorf("Add() got = %v, want [3]", second)
+	}
+}
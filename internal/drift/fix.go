@@ -0,0 +1,128 @@
+package drift
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/model"
+)
+
+// Fix is a tag update of a pbuf.yaml module recommended by the dependency drift status.
+// Skipped fixes have the reason they were not applied
+type Fix struct {
+	Module   string
+	From     string
+	To       string
+	Severity v1.DriftSeverity
+	Reason   string
+}
+
+// PlanFixes returns the tag updates of the registry modules of the config
+// recommended by the dependency drift statuses, and the recommendations skipped.
+// Review recommendations are applied only with includeReview
+func PlanFixes(config *model.Config, modules []*ModuleDependencies, includeReview bool) (fixes, skipped []*Fix) {
+	pinned := map[string]string{}
+	for _, module := range config.Modules {
+		if module.Repository == "" {
+			pinned[module.Name] = module.Tag
+		}
+	}
+
+	planned := map[string]*Fix{}
+	for _, module := range modules {
+		for _, status := range module.Statuses {
+			name := status.GetDependencyName()
+			fix := &Fix{
+				Module:   name,
+				From:     pinned[name],
+				To:       status.GetTargetTag(),
+				Severity: status.GetSeverity(),
+			}
+
+			tag, ok := pinned[name]
+			switch {
+			case !ok:
+				fix.From = status.GetCurrentTag()
+				fix.Reason = fmt.Sprintf("not in %s, required by %s@%s", model.PbufConfigFilename, module.Name, module.Tag)
+			case fix.To == "":
+				fix.Reason = "no target tag"
+			case fix.To == tag:
+				continue
+			case status.GetRecommendation() == v1.DependencyDriftRecommendation_DEPENDENCY_DRIFT_RECOMMENDATION_ALERT_REVIEW && !includeReview:
+				fix.Reason = "needs review"
+			case status.GetRecommendation() != v1.DependencyDriftRecommendation_DEPENDENCY_DRIFT_RECOMMENDATION_SUGGEST_UPDATE &&
+				status.GetRecommendation() != v1.DependencyDriftRecommendation_DEPENDENCY_DRIFT_RECOMMENDATION_ALERT_REVIEW:
+				fix.Reason = "no update recommended"
+			}
+
+			if fix.Reason != "" {
+				skipped = append(skipped, fix)
+				continue
+			}
+
+			previous, ok := planned[name]
+			if !ok {
+				planned[name] = fix
+				fixes = append(fixes, fix)
+				continue
+			}
+			if previous.To != fix.To {
+				previous.Reason = fmt.Sprintf("conflicting target tags %s and %s", previous.To, fix.To)
+			}
+		}
+	}
+
+	// conflicting fixes are not applied
+	applied := fixes[:0]
+	for _, fix := range fixes {
+		if fix.Reason != "" {
+			skipped = append(skipped, fix)
+			continue
+		}
+		applied = append(applied, fix)
+	}
+
+	return applied, skipped
+}
+
+// ApplyFixes updates the tags of the config modules
+func ApplyFixes(config *model.Config, fixes []*Fix) {
+	for _, fix := range fixes {
+		config.AddModule(fix.Module, fix.To)
+	}
+}
+
+// FixSummary describes the applied and skipped fixes in the form of a commit message, e.g.
+//
+//	Update 1 drifted dependency
+//
+//	- acme/common: v1.0.0 -> v1.2.0 (warning)
+func FixSummary(fixes, skipped []*Fix) string {
+	var summary strings.Builder
+
+	switch len(fixes) {
+	case 0:
+		summary.WriteString("No drifted dependencies to update\n")
+	case 1:
+		summary.WriteString("Update 1 drifted dependency\n")
+	default:
+		fmt.Fprintf(&summary, "Update %d drifted dependencies\n", len(fixes))
+	}
+
+	if len(fixes) > 0 {
+		summary.WriteString("\n")
+		for _, fix := range fixes {
+			fmt.Fprintf(&summary, "- %s: %s -> %s (%s)\n", fix.Module, fix.From, fix.To, SeverityName(fix.Severity))
+		}
+	}
+
+	if len(skipped) > 0 {
+		summary.WriteString("\nSkipped:\n")
+		for _, fix := range skipped {
+			fmt.Fprintf(&summary, "- %s: %s -> %s, %s\n", fix.Module, fix.From, fix.To, fix.Reason)
+		}
+	}
+
+	return summary.String()
+}
@@ -0,0 +1,102 @@
+package drift
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/model"
+)
+
+func TestPlanFixes(t *testing.T) {
+	pinned := &model.Config{
+		Modules: []*model.Module{
+			{Name: "acme/payments", Tag: "v1.0.0"},
+			{Name: "acme/common", Tag: "v1.0.0"},
+			{Name: "acme/auth", Tag: "v1.0.0"},
+			{Name: "googleapis", Repository: "https://github.com/googleapis/googleapis", Tag: "master"},
+		},
+	}
+
+	modules := []*ModuleDependencies{
+		{
+			Name: "acme/payments",
+			Tag:  "v1.0.0",
+			Statuses: []*v1.DependencyDriftStatus{
+				{
+					DependencyName: "acme/common",
+					CurrentTag:     "v1.0.0",
+					TargetTag:      "v1.2.0",
+					Severity:       v1.DriftSeverity_DRIFT_SEVERITY_WARNING,
+					Recommendation: v1.DependencyDriftRecommendation_DEPENDENCY_DRIFT_RECOMMENDATION_SUGGEST_UPDATE,
+				},
+				{
+					DependencyName: "acme/auth",
+					CurrentTag:     "v1.0.0",
+					TargetTag:      "v2.0.0",
+					Severity:       v1.DriftSeverity_DRIFT_SEVERITY_CRITICAL,
+					Recommendation: v1.DependencyDriftRecommendation_DEPENDENCY_DRIFT_RECOMMENDATION_ALERT_REVIEW,
+				},
+				{
+					DependencyName: "acme/money",
+					CurrentTag:     "v0.1.0",
+					TargetTag:      "v0.2.0",
+					Severity:       v1.DriftSeverity_DRIFT_SEVERITY_INFO,
+					Recommendation: v1.DependencyDriftRecommendation_DEPENDENCY_DRIFT_RECOMMENDATION_SUGGEST_UPDATE,
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		includeReview bool
+		want          string
+		wantTags      map[string]string
+	}{
+		{
+			name: "suggested updates",
+			want: "Update 1 drifted dependency\n\n" +
+				"- acme/common: v1.0.0 -> v1.2.0 (warning)\n\n" +
+				"Skipped:\n" +
+				"- acme/auth: v1.0.0 -> v2.0.0, needs review\n" +
+				"- acme/money: v0.1.0 -> v0.2.0, not in pbuf.yaml, required by acme/payments@v1.0.0\n",
+			wantTags: map[string]string{"acme/payments": "v1.0.0", "acme/common": "v1.2.0", "acme/auth": "v1.0.0"},
+		},
+		{
+			name:          "include review",
+			includeReview: true,
+			want: "Update 2 drifted dependencies\n\n" +
+				"- acme/common: v1.0.0 -> v1.2.0 (warning)\n" +
+				"- acme/auth: v1.0.0 -> v2.0.0 (critical)\n\n" +
+				"Skipped:\n" +
+				"- acme/money: v0.1.0 -> v0.2.0, not in pbuf.yaml, required by acme/payments@v1.0.0\n",
+			wantTags: map[string]string{"acme/payments": "v1.0.0", "acme/common": "v1.2.0", "acme/auth": "v2.0.0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &model.Config{}
+			for _, module := range pinned.Modules {
+				copied := *module
+				config.Modules = append(config.Modules, &copied)
+			}
+
+			fixes, skipped := PlanFixes(config, modules, tt.includeReview)
+			if got := FixSummary(fixes, skipped); got != tt.want {
+				t.Errorf("FixSummary() got = %q, want %q", got, tt.want)
+			}
+
+			ApplyFixes(config, fixes)
+			tags := map[string]string{}
+			for _, module := range config.Modules {
+				if module.Repository == "" {
+					tags[module.Name] = module.Tag
+				}
+			}
+			if !reflect.DeepEqual(tags, tt.wantTags) {
+				t.Errorf("ApplyFixes() got = %v, want %v", tags, tt.wantTags)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package drift
+
+import (
+	"os"
+	"sort"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/registry"
+)
+
+// Local file statuses
+const (
+	StatusModifiedLocally = "modified locally"
+	StatusDeletedLocally  = "deleted locally"
+	StatusChangedUpstream = "changed upstream"
+	StatusAddedUpstream   = "added upstream"
+	StatusDeletedUpstream = "deleted upstream"
+	StatusNotVendored     = "not vendored"
+)
+
+// LocalFile is a vendored file that differs from the recorded or the registry content
+type LocalFile struct {
+	Module   string   `json:"module"`
+	Tag      string   `json:"tag"`
+	Filename string   `json:"filename"`
+	Path     string   `json:"path"`
+	Status   string   `json:"status"`
+	Events   []string `json:"events,omitempty"`
+}
+
+// UpstreamFile is a registry file of the module tag with its local path
+type UpstreamFile struct {
+	Filename string
+	Path     string
+	Hash     string
+}
+
+// CompareLocal compares the vendored files of the locked module with their recorded hashes
+// and with the current registry files. The changed upstream files are annotated
+// with the ids of the drift events of the file
+func CompareLocal(locked *registry.LockedModule, upstream []*UpstreamFile, events []*v1.DriftEvent) ([]*LocalFile, error) {
+	var files []*LocalFile
+	add := func(filename, path, status string) *LocalFile {
+		file := &LocalFile{
+			Module:   locked.Name,
+			Tag:      locked.Tag,
+			Filename: filename,
+			Path:     path,
+			Status:   status,
+		}
+		files = append(files, file)
+		return file
+	}
+
+	current := map[string]*UpstreamFile{}
+	for _, file := range upstream {
+		current[file.Filename] = file
+	}
+
+	vendored := map[string]bool{}
+	for _, lockedFile := range locked.Files {
+		vendored[lockedFile.Filename] = true
+
+		content, err := os.ReadFile(lockedFile.Path)
+		switch {
+		case os.IsNotExist(err):
+			add(lockedFile.Filename, lockedFile.Path, StatusDeletedLocally)
+		case err != nil:
+			return nil, err
+		case registry.FileHash(content) != lockedFile.VendoredHash():
+			add(lockedFile.Filename, lockedFile.Path, StatusModifiedLocally)
+		}
+
+		upstreamFile, ok := current[lockedFile.Filename]
+		switch {
+		case !ok:
+			file := add(lockedFile.Filename, lockedFile.Path, StatusDeletedUpstream)
+			file.Events = eventIDs(events, lockedFile.Filename, lockedFile.Hash, "")
+		case upstreamFile.Hash != lockedFile.Hash:
+			file := add(lockedFile.Filename, lockedFile.Path, StatusChangedUpstream)
+			file.Events = eventIDs(events, lockedFile.Filename, lockedFile.Hash, upstreamFile.Hash)
+		}
+	}
+
+	for _, file := range upstream {
+		if !vendored[file.Filename] {
+			added := add(file.Filename, file.Path, StatusAddedUpstream)
+			added.Events = eventIDs(events, file.Filename, "", file.Hash)
+		}
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Filename < files[j].Filename
+	})
+
+	return files, nil
+}
+
+// eventIDs returns the ids of the drift events of the file
+// starting from the vendored hash or ending with the current one
+func eventIDs(events []*v1.DriftEvent, filename, previousHash, currentHash string) []string {
+	var ids []string
+	for _, event := range events {
+		if event.GetFilename() != filename {
+			continue
+		}
+		if event.GetPreviousHash() == previousHash || event.GetCurrentHash() == currentHash {
+			ids = append(ids, event.GetId())
+		}
+	}
+	return ids
+}
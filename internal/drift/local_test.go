@@ -0,0 +1,73 @@
+package drift
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/registry"
+)
+
+func TestCompareLocal(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	hash := func(content string) string {
+		return registry.FileHash([]byte(content))
+	}
+
+	locked := &registry.LockedModule{
+		Name: "acme/payments",
+		Tag:  "v1.0.0",
+		Files: []*registry.LockedFile{
+			{Filename: "a.proto", Path: write("a.proto", "a"), Hash: hash("a")},
+			{Filename: "b.proto", Path: write("b.proto", "b edited"), Hash: hash("b")},
+			{Filename: "c.proto", Path: filepath.Join(dir, "c.proto"), Hash: hash("c")},
+			{Filename: "d.proto", Path: write("d.proto", "d patched"), Hash: hash("d"), LocalHash: hash("d patched")},
+			{Filename: "e.proto", Path: write("e.proto", "e"), Hash: hash("e")},
+		},
+	}
+
+	upstream := []*UpstreamFile{
+		{Filename: "a.proto", Hash: hash("a")},
+		{Filename: "b.proto", Hash: hash("b")},
+		{Filename: "c.proto", Hash: hash("c")},
+		{Filename: "d.proto", Hash: hash("d v2")},
+		{Filename: "f.proto", Path: "f.proto", Hash: hash("f")},
+	}
+
+	events := []*v1.DriftEvent{
+		{Id: "1", Filename: "d.proto", PreviousHash: hash("d"), CurrentHash: hash("d v2")},
+		{Id: "2", Filename: "a.proto", PreviousHash: hash("a"), CurrentHash: hash("a")},
+		{Id: "3", Filename: "e.proto", PreviousHash: hash("e")},
+	}
+
+	files, err := CompareLocal(locked, upstream, events)
+	if err != nil {
+		t.Fatalf("CompareLocal() error = %v", err)
+	}
+
+	var got [][]string
+	for _, file := range files {
+		got = append(got, append([]string{file.Filename, file.Status}, file.Events...))
+	}
+
+	want := [][]string{
+		{"b.proto", StatusModifiedLocally},
+		{"c.proto", StatusDeletedLocally},
+		{"d.proto", StatusChangedUpstream, "1"},
+		{"e.proto", StatusDeletedUpstream, "3"},
+		{"f.proto", StatusAddedUpstream},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CompareLocal() got = %v, want %v", got, want)
+	}
+}
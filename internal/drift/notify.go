@@ -0,0 +1,197 @@
+package drift
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/output"
+)
+
+// Payload kinds
+const (
+	PayloadGeneric = "generic"
+	PayloadSlack   = "slack"
+)
+
+// Payloads are the built-in payload kinds
+var Payloads = []string{PayloadGeneric, PayloadSlack}
+
+// NotifyState records the delivered events, so they are not sent twice
+type NotifyState struct {
+	LastEventID string   `json:"last_event_id,omitempty"`
+	Delivered   []string `json:"delivered,omitempty"`
+}
+
+// NotifyStatePath returns the default state file of the registry and the webhook
+// in the user cache directory, e.g. `~/.cache/pbuf/drift-notify-<hash>.json`.
+// The webhook is hashed, as its URL often contains a secret
+func NotifyStatePath(registryAddr, webhook string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user cache directory: %w", err)
+	}
+
+	sum := sha256.Sum256([]byte(registryAddr + "\n" + webhook))
+	return filepath.Join(dir, "pbuf", "drift-notify-"+hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// LoadNotifyState reads the state file. It returns an empty state if the file does not exist
+func LoadNotifyState(path string) (*NotifyState, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &NotifyState{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	state := &NotifyState{}
+	if err := json.Unmarshal(content, state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return state, nil
+}
+
+// Save writes the state file
+func (s *NotifyState) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	content, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Pending returns the events not delivered yet
+func (s *NotifyState) Pending(events []*v1.DriftEvent) []*v1.DriftEvent {
+	delivered := map[string]bool{}
+	for _, id := range s.Delivered {
+		delivered[id] = true
+	}
+
+	var pending []*v1.DriftEvent
+	for _, event := range events {
+		if !delivered[event.GetId()] {
+			pending = append(pending, event)
+		}
+	}
+	return pending
+}
+
+// MarkDelivered records the delivered events. The ids of the events no longer
+// listed, e.g. acknowledged, are forgotten to keep the state small
+func (s *NotifyState) MarkDelivered(delivered, listed []*v1.DriftEvent) {
+	keep := map[string]bool{}
+	for _, event := range listed {
+		keep[event.GetId()] = true
+	}
+
+	var ids []string
+	for _, id := range s.Delivered {
+		if keep[id] {
+			ids = append(ids, id)
+		}
+	}
+	for _, event := range delivered {
+		ids = append(ids, event.GetId())
+		s.LastEventID = event.GetId()
+	}
+	s.Delivered = ids
+}
+
+// CheckPayload returns an error if the payload kind is not built-in
+func CheckPayload(kind string) error {
+	if !slices.Contains(Payloads, kind) {
+		return fmt.Errorf("unknown payload %s, expected one of: %s", kind, strings.Join(Payloads, ", "))
+	}
+	return nil
+}
+
+// Payload builds the webhook body of the built-in kind
+func Payload(kind string, events []*v1.DriftEvent) ([]byte, error) {
+	switch kind {
+	case PayloadGeneric:
+		values, err := output.Value(events)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]any{"events": values})
+	case PayloadSlack:
+		var text strings.Builder
+		fmt.Fprintf(&text, "%d new drift events", len(events))
+		for _, event := range events {
+			fmt.Fprintf(&text, "\n• *%s* %s@%s `%s` %s (id %s)",
+				strings.ToUpper(SeverityName(event.GetSeverity())),
+				event.GetModuleName(), event.GetTagName(), event.GetFilename(),
+				strings.ToLower(strings.TrimPrefix(event.GetEventType().String(), eventTypePrefix)),
+				event.GetId())
+		}
+		return json.Marshal(map[string]string{"text": text.String()})
+	default:
+		return nil, CheckPayload(kind)
+	}
+}
+
+// TemplatePayload builds the webhook body with the Go template
+// executed on the events in the JSON form, e.g. `{{range .events}}{{.id}}{{end}}`
+func TemplatePayload(text string, events []*v1.DriftEvent) ([]byte, error) {
+	tmpl, err := template.New("payload").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse payload template: %w", err)
+	}
+
+	values, err := output.Value(events)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, map[string]any{"events": values}); err != nil {
+		return nil, fmt.Errorf("failed to execute payload template: %w", err)
+	}
+
+	return body.Bytes(), nil
+}
+
+// Post sends the JSON body to the webhook. Non 2xx responses are errors
+func Post(ctx context.Context, client *http.Client, url string, body []byte) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to post to webhook: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("webhook responded %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package drift
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+)
+
+func TestNotify(t *testing.T) {
+	var received []string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Post() got content type = %s", r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	events := []*v1.DriftEvent{
+		{
+			Id:         "1",
+			ModuleName: "acme/payments",
+			TagName:    "v1.0.0",
+			Filename:   "a.proto",
+			EventType:  v1.DriftEventType_DRIFT_EVENT_TYPE_MODIFIED,
+			Severity:   v1.DriftSeverity_DRIFT_SEVERITY_CRITICAL,
+		},
+	}
+
+	tests := []struct {
+		name    string
+		payload func() ([]byte, error)
+		want    string
+	}{
+		{
+			name:    "generic",
+			payload: func() ([]byte, error) { return Payload(PayloadGeneric, events) },
+			want: `{"events":[{"event_type":"DRIFT_EVENT_TYPE_MODIFIED","filename":"a.proto","id":"1",` +
+				`"module_name":"acme/payments","severity":"DRIFT_SEVERITY_CRITICAL","tag_name":"v1.0.0"}]}`,
+		},
+		{
+			name:    "slack",
+			payload: func() ([]byte, error) { return Payload(PayloadSlack, events) },
+			want:    `{"text":"1 new drift events\n• *CRITICAL* acme/payments@v1.0.0 ` + "`a.proto`" + ` modified (id 1)"}`,
+		},
+		{
+			name: "template",
+			payload: func() ([]byte, error) {
+				return TemplatePayload(`{"ids":[{{range .events}}"{{.id}}"{{end}}]}`, events)
+			},
+			want: `{"ids":["1"]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := tt.payload()
+			if err != nil {
+				t.Fatalf("payload error = %v", err)
+			}
+
+			received = nil
+			if err := Post(context.Background(), server.Client(), server.URL, body); err != nil {
+				t.Fatalf("Post() error = %v", err)
+			}
+
+			if len(received) != 1 || received[0] != tt.want {
+				t.Errorf("Post() got = %v, want %v", received, tt.want)
+			}
+		})
+	}
+
+	if err := CheckPayload("slak"); err == nil {
+		t.Errorf("CheckPayload() expected error for an unknown payload")
+	}
+
+	status = http.StatusInternalServerError
+	if err := Post(context.Background(), server.Client(), server.URL, []byte("{}")); err == nil {
+		t.Errorf("Post() expected error for status %d", status)
+	}
+}
+
+func TestNotifyState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	state, err := LoadNotifyState(path)
+	if err != nil {
+		t.Fatalf("LoadNotifyState() error = %v", err)
+	}
+
+	first := []*v1.DriftEvent{{Id: "1"}, {Id: "2"}}
+	if pending := state.Pending(first); len(pending) != 2 {
+		t.Errorf("Pending() got = %v, want all events", pending)
+	}
+	state.MarkDelivered(first, first)
+
+	if err := state.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	state, err = LoadNotifyState(path)
+	if err != nil {
+		t.Fatalf("LoadNotifyState() error = %v", err)
+	}
+
+	// event 1 is acknowledged, event 3 is new
+	second := []*v1.DriftEvent{{Id: "2"}, {Id: "3"}}
+	pending := state.Pending(second)
+	if len(pending) != 1 || pending[0].Id != "3" {
+		t.Errorf("Pending() got = %v, want [3]", pending)
+	}
+	state.MarkDelivered(pending, second)
+
+	want := &NotifyState{LastEventID: "3", Delivered: []string{"2", "3"}}
+	if !reflect.DeepEqual(state, want) {
+		t.Errorf("MarkDelivered() got = %+v, want %+v", state, want)
+	}
+}
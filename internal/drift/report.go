@@ -0,0 +1,110 @@
+package drift
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/output"
+)
+
+const (
+	eventTypePrefix      = "DRIFT_EVENT_TYPE_"
+	recommendationPrefix = "DEPENDENCY_DRIFT_RECOMMENDATION_"
+)
+
+var eventRules = []output.Rule{
+	{ID: "drift/added", Description: "A file was added to the module tag after it was published"},
+	{ID: "drift/modified", Description: "A file of the module tag was modified after it was published"},
+	{ID: "drift/deleted", Description: "A file was deleted from the module tag after it was published"},
+}
+
+var dependencyRules = []output.Rule{
+	{ID: "dependency-drift/suggest-update", Description: "A newer tag of the dependency is available"},
+	{ID: "dependency-drift/alert-review", Description: "The dependency drifted and needs a review before updating"},
+}
+
+// Level maps the drift severity to the finding level
+func Level(severity v1.DriftSeverity) output.Level {
+	switch severity {
+	case v1.DriftSeverity_DRIFT_SEVERITY_CRITICAL:
+		return output.LevelError
+	case v1.DriftSeverity_DRIFT_SEVERITY_WARNING:
+		return output.LevelWarning
+	default:
+		return output.LevelNote
+	}
+}
+
+// EventsReport converts the drift events to findings, one per event
+func EventsReport(events []*v1.DriftEvent) *output.Report {
+	report := &output.Report{Name: "drift", Rules: eventRules}
+
+	for _, event := range events {
+		eventType := strings.ToLower(strings.TrimPrefix(event.GetEventType().String(), eventTypePrefix))
+		module := event.GetModuleName() + "@" + event.GetTagName()
+
+		properties := map[string]string{
+			"id":           event.GetId(),
+			"module":       event.GetModuleName(),
+			"tag":          event.GetTagName(),
+			"severity":     SeverityName(event.GetSeverity()),
+			"acknowledged": fmt.Sprint(event.GetAcknowledged()),
+		}
+		if event.GetPreviousHash() != "" {
+			properties["previous_hash"] = event.GetPreviousHash()
+		}
+		if event.GetCurrentHash() != "" {
+			properties["current_hash"] = event.GetCurrentHash()
+		}
+		if event.GetDetectedAt() != nil {
+			properties["detected_at"] = event.GetDetectedAt().AsTime().Format(time.RFC3339)
+		}
+
+		report.Findings = append(report.Findings, &output.Finding{
+			RuleID:     "drift/" + eventType,
+			Level:      Level(event.GetSeverity()),
+			Message:    fmt.Sprintf("%s was %s in %s", event.GetFilename(), eventType, module),
+			Group:      module,
+			Name:       event.GetFilename(),
+			Location:   event.GetFilename(),
+			Properties: properties,
+		})
+	}
+
+	return report
+}
+
+// DependenciesReport converts the dependency drift statuses of the module to findings,
+// one per dependency. Statuses without a recommendation have no rule and are skipped
+func DependenciesReport(moduleName string, statuses []*v1.DependencyDriftStatus) *output.Report {
+	report := &output.Report{Name: "dependency-drift", Rules: dependencyRules}
+
+	for _, status := range statuses {
+		if status.GetRecommendation() == v1.DependencyDriftRecommendation_DEPENDENCY_DRIFT_RECOMMENDATION_UNSPECIFIED {
+			continue
+		}
+
+		recommendation := RecommendationName(status.GetRecommendation())
+
+		report.Findings = append(report.Findings, &output.Finding{
+			RuleID: "dependency-drift/" + recommendation,
+			Level:  Level(status.GetSeverity()),
+			Message: fmt.Sprintf("%s of %s is pinned to %s, target %s: %s",
+				status.GetDependencyName(), moduleName, status.GetCurrentTag(), status.GetTargetTag(),
+				strings.ReplaceAll(recommendation, "-", " ")),
+			Group: moduleName,
+			Name:  status.GetDependencyName(),
+			Properties: map[string]string{
+				"dependency":     status.GetDependencyName(),
+				"current_tag":    status.GetCurrentTag(),
+				"target_tag":     status.GetTargetTag(),
+				"severity":       SeverityName(status.GetSeverity()),
+				"recommendation": recommendation,
+			},
+		})
+	}
+
+	return report
+}
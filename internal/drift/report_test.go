@@ -0,0 +1,81 @@
+package drift
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/output"
+)
+
+func TestReports(t *testing.T) {
+	events := EventsReport([]*v1.DriftEvent{
+		{
+			Id:         "1",
+			ModuleName: "acme/payments",
+			TagName:    "v1.0.0",
+			Filename:   "a.proto",
+			EventType:  v1.DriftEventType_DRIFT_EVENT_TYPE_MODIFIED,
+			Severity:   v1.DriftSeverity_DRIFT_SEVERITY_CRITICAL,
+		},
+	})
+
+	dependencies := DependenciesReport("acme/orders", []*v1.DependencyDriftStatus{
+		{
+			DependencyName: "acme/payments",
+			CurrentTag:     "v1.0.0",
+			TargetTag:      "v1.1.0",
+			Severity:       v1.DriftSeverity_DRIFT_SEVERITY_INFO,
+			Recommendation: v1.DependencyDriftRecommendation_DEPENDENCY_DRIFT_RECOMMENDATION_SUGGEST_UPDATE,
+		},
+		{
+			DependencyName: "acme/common",
+			CurrentTag:     "v1.0.0",
+			Recommendation: v1.DependencyDriftRecommendation_DEPENDENCY_DRIFT_RECOMMENDATION_UNSPECIFIED,
+		},
+	})
+
+	// statuses without a recommendation have no rule
+	if len(dependencies.Findings) != 1 {
+		t.Errorf("DependenciesReport() got %d findings, want 1", len(dependencies.Findings))
+	}
+
+	tests := []struct {
+		name    string
+		finding *output.Finding
+		want    output.Finding
+	}{
+		{
+			name:    "event",
+			finding: events.Findings[0],
+			want: output.Finding{
+				RuleID:   "drift/modified",
+				Level:    output.LevelError,
+				Message:  "a.proto was modified in acme/payments@v1.0.0",
+				Group:    "acme/payments@v1.0.0",
+				Name:     "a.proto",
+				Location: "a.proto",
+			},
+		},
+		{
+			name:    "dependency",
+			finding: dependencies.Findings[0],
+			want: output.Finding{
+				RuleID:  "dependency-drift/suggest-update",
+				Level:   output.LevelNote,
+				Message: "acme/payments of acme/orders is pinned to v1.0.0, target v1.1.0: suggest update",
+				Group:   "acme/orders",
+				Name:    "acme/payments",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := *tt.finding
+			got.Properties = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("finding got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package drift
+
+import (
+	"fmt"
+	"os"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/breaking"
+	"github.com/pbufio/pbuf-cli/internal/diff"
+	"github.com/pbufio/pbuf-cli/internal/registry"
+	"github.com/pbufio/pbuf-cli/internal/schema"
+)
+
+// EventDiff is the content change of the file of a drift event
+type EventDiff struct {
+	Event *v1.DriftEvent
+	// PreviousSource describes where the previous content was found
+	PreviousSource string
+	Diff           string
+	Changes        []*breaking.Change
+}
+
+// Value returns the event diff in the form rendered by the json, yaml and template formats
+func (d *EventDiff) Value() map[string]any {
+	return map[string]any{
+		"event":           d.Event,
+		"previous_source": d.PreviousSource,
+		"diff":            d.Diff,
+		"changes":         d.Changes,
+	}
+}
+
+// PreviousContent finds the content of the event file with the previous hash,
+// first in the snapshots, then in the vendored file recorded in the lock.
+// It returns false if the content is not available locally
+func PreviousContent(event *v1.DriftEvent, lock *registry.Lock, snapshotDir string) ([]byte, string, bool, error) {
+	hash := event.GetPreviousHash()
+	if hash == "" {
+		return nil, "", false, nil
+	}
+
+	if snapshotDir != "" {
+		content, ok, err := registry.LoadSnapshot(snapshotDir, hash)
+		if err != nil {
+			return nil, "", false, err
+		}
+		if ok {
+			return content, "snapshot " + hash, true, nil
+		}
+	}
+
+	module := lock.Module(event.GetModuleName())
+	if module == nil {
+		return nil, "", false, nil
+	}
+
+	for _, file := range module.Files {
+		if file.Filename != event.GetFilename() || file.VendoredHash() != hash {
+			continue
+		}
+
+		content, err := os.ReadFile(file.Path)
+		if os.IsNotExist(err) {
+			return nil, "", false, nil
+		}
+		if err != nil {
+			return nil, "", false, fmt.Errorf("failed to read %s: %w", file.Path, err)
+		}
+
+		// the vendored file may be edited after vendoring
+		if registry.FileHash(content) != hash {
+			return nil, "", false, nil
+		}
+
+		return content, file.Path, true, nil
+	}
+
+	return nil, "", false, nil
+}
+
+// NewEventDiff compares the previous and the current content of the event file.
+// The content is nil when the file did not exist
+func NewEventDiff(event *v1.DriftEvent, source string, previous, current []byte) (*EventDiff, error) {
+	filename := event.GetFilename()
+	module := event.GetModuleName() + "@" + event.GetTagName()
+
+	fromName, toName := "a/"+filename, "b/"+filename
+	if previous == nil {
+		fromName = "/dev/null"
+	}
+	if current == nil {
+		toName = "/dev/null"
+	}
+
+	eventDiff := &EventDiff{
+		Event:          event,
+		PreviousSource: source,
+		Diff:           diff.Unified(fromName, toName, string(previous), string(current)),
+		Changes:        []*breaking.Change{},
+	}
+
+	previousFiles, err := parseContent(filename, previous)
+	if err != nil {
+		return nil, fmt.Errorf("previous content of %s: %w", module, err)
+	}
+
+	currentFiles, err := parseContent(filename, current)
+	if err != nil {
+		return nil, fmt.Errorf("current content of %s: %w", module, err)
+	}
+
+	if changes := breaking.Compare(previousFiles, currentFiles, breaking.RuleSetSource); changes != nil {
+		eventDiff.Changes = changes
+	}
+
+	return eventDiff, nil
+}
+
+func parseContent(filename string, content []byte) ([]*schema.File, error) {
+	if content == nil {
+		return nil, nil
+	}
+
+	return schema.ParseAll([]*v1.ProtoFile{{Filename: filename, Content: string(content)}})
+}
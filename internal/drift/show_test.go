@@ -0,0 +1,91 @@
+package drift
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/breaking"
+	"github.com/pbufio/pbuf-cli/internal/output"
+	"github.com/pbufio/pbuf-cli/internal/registry"
+)
+
+const (
+	previousProto = "syntax = \"proto3\";\npackage acme.v1;\n\nmessage Payment {\n  string id = 1;\n  int64 amount = 2;\n}\n"
+	currentProto  = "syntax = \"proto3\";\npackage acme.v1;\n\nmessage Payment {\n  string id = 1;\n}\n"
+)
+
+func TestPreviousContent(t *testing.T) {
+	dir := t.TempDir()
+	snapshotDir := filepath.Join(dir, "snapshots")
+	vendored := filepath.Join(dir, "payment.proto")
+	if err := os.WriteFile(vendored, []byte(previousProto), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	hash := registry.FileHash([]byte(previousProto))
+	lock := &registry.Lock{Modules: []*registry.LockedModule{
+		{
+			Name:  "acme/payments",
+			Tag:   "v1.0.0",
+			Files: []*registry.LockedFile{{Filename: "payment.proto", Path: vendored, Hash: hash}},
+		},
+	}}
+	event := &v1.DriftEvent{ModuleName: "acme/payments", Filename: "payment.proto", PreviousHash: hash}
+
+	content, source, ok, err := PreviousContent(event, lock, snapshotDir)
+	if err != nil || !ok || string(content) != previousProto || source != vendored {
+		t.Errorf("PreviousContent() got = %v, %v, %v, want vendored file", source, ok, err)
+	}
+
+	// the snapshot is preferred, the vendored file may be changed later
+	if err := registry.SaveSnapshot(snapshotDir, []byte(previousProto)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(vendored, []byte(currentProto), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	content, source, ok, err = PreviousContent(event, lock, snapshotDir)
+	if err != nil || !ok || string(content) != previousProto || source != "snapshot "+hash {
+		t.Errorf("PreviousContent() got = %v, %v, %v, want snapshot", source, ok, err)
+	}
+
+	_, _, ok, err = PreviousContent(&v1.DriftEvent{ModuleName: "acme/payments", PreviousHash: "../x"}, lock, snapshotDir)
+	if err != nil || ok {
+		t.Errorf("PreviousContent() got = %v, %v, want not found", ok, err)
+	}
+}
+
+func TestNewEventDiff(t *testing.T) {
+	event := &v1.DriftEvent{
+		Id:         "1",
+		ModuleName: "acme/payments",
+		TagName:    "v1.0.0",
+		Filename:   "payment.proto",
+		EventType:  v1.DriftEventType_DRIFT_EVENT_TYPE_MODIFIED,
+	}
+
+	eventDiff, err := NewEventDiff(event, "snapshot", []byte(previousProto), []byte(currentProto))
+	if err != nil {
+		t.Fatalf("NewEventDiff() error = %v", err)
+	}
+
+	if !strings.Contains(eventDiff.Diff, "-  int64 amount = 2;\n") {
+		t.Errorf("NewEventDiff() got diff = %s", eventDiff.Diff)
+	}
+
+	if len(eventDiff.Changes) != 1 || eventDiff.Changes[0].Rule != breaking.RuleFieldNoDelete {
+		t.Errorf("NewEventDiff() got changes = %v, want %s", eventDiff.Changes, breaking.RuleFieldNoDelete)
+	}
+
+	value, err := output.Value(eventDiff.Value())
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+	if got := value.(map[string]any)["event"].(map[string]any)["event_type"]; got != "DRIFT_EVENT_TYPE_MODIFIED" {
+		t.Errorf("Value() got event type = %v", got)
+	}
+}
@@ -0,0 +1,33 @@
+package drift
+
+import (
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+)
+
+// AtLeast reports whether the severity is at or above the threshold
+func AtLeast(severity, threshold v1.DriftSeverity) bool {
+	return severity >= threshold
+}
+
+// Seen tracks the drift events already reported by their ids
+type Seen struct {
+	ids map[string]bool
+}
+
+// NewSeen creates an empty set of reported events
+func NewSeen() *Seen {
+	return &Seen{ids: map[string]bool{}}
+}
+
+// Add marks the events as reported and returns the ones not reported before
+func (s *Seen) Add(events []*v1.DriftEvent) []*v1.DriftEvent {
+	var added []*v1.DriftEvent
+	for _, event := range events {
+		if s.ids[event.GetId()] {
+			continue
+		}
+		s.ids[event.GetId()] = true
+		added = append(added, event)
+	}
+	return added
+}
@@ -0,0 +1,190 @@
+package format
+
+import (
+	"strings"
+)
+
+// formatAggregate formats the message literal of an option value, e.g.
+// `{get:"/v1/modules"\nbody:"*"}`. Multiline literals are indented
+// with the given indent, inline literals are printed on a single line
+func formatAggregate(constant string, indent string, multiline bool) string {
+	tokens := tokenizeAggregate(constant)
+	if len(tokens) == 0 || tokens[0] != "{" {
+		return constant
+	}
+
+	p := &aggregatePrinter{tokens: tokens, multiline: multiline}
+	p.printMessage(indent)
+
+	if p.pos != len(tokens) {
+		// not a valid text format literal, keep it as is
+		return constant
+	}
+
+	return p.out.String()
+}
+
+type aggregatePrinter struct {
+	tokens    []string
+	pos       int
+	multiline bool
+	out       strings.Builder
+}
+
+func (p *aggregatePrinter) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *aggregatePrinter) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+// printMessage prints `{ field: value ... }` starting at the current token
+func (p *aggregatePrinter) printMessage(indent string) {
+	p.next() // {
+	p.out.WriteString("{")
+
+	first := true
+	for p.pos < len(p.tokens) && p.peek() != "}" {
+		if p.peek() == "," || p.peek() == ";" {
+			p.next()
+			continue
+		}
+
+		if p.multiline {
+			p.out.WriteString("\n" + indent + indentUnit)
+		} else if !first {
+			p.out.WriteString(", ")
+		}
+		first = false
+
+		p.printField(indent + indentUnit)
+	}
+
+	if p.multiline && !first {
+		p.out.WriteString("\n" + indent)
+	}
+
+	if p.peek() == "}" {
+		p.next()
+		p.out.WriteString("}")
+	}
+}
+
+func (p *aggregatePrinter) printField(indent string) {
+	if p.peek() == "[" {
+		// extension or Any type URL name
+		for p.pos < len(p.tokens) {
+			token := p.next()
+			p.out.WriteString(token)
+			if token == "]" {
+				break
+			}
+		}
+	} else {
+		p.out.WriteString(p.next())
+	}
+
+	if p.peek() == ":" {
+		p.next()
+	}
+
+	if p.peek() == "{" {
+		// the colon is optional before message values
+		p.out.WriteString(" ")
+		p.printMessage(indent)
+		return
+	}
+
+	p.out.WriteString(": ")
+	p.printValue(indent)
+}
+
+func (p *aggregatePrinter) printValue(indent string) {
+	switch p.peek() {
+	case "{":
+		p.printMessage(indent)
+	case "[":
+		p.next()
+		p.out.WriteString("[")
+		first := true
+		for p.pos < len(p.tokens) && p.peek() != "]" {
+			if p.peek() == "," {
+				p.next()
+				continue
+			}
+			if !first {
+				p.out.WriteString(", ")
+			}
+			first = false
+			p.printValue(indent)
+		}
+		if p.peek() == "]" {
+			p.next()
+			p.out.WriteString("]")
+		}
+	default:
+		token := p.next()
+		p.out.WriteString(token)
+		// adjacent strings are concatenated
+		for isString(token) && isString(p.peek()) {
+			token = p.next()
+			p.out.WriteString(" " + token)
+		}
+	}
+}
+
+// tokenizeAggregate splits the message literal into
+// punctuation, strings and identifiers
+func tokenizeAggregate(s string) []string {
+	var tokens []string
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.IndexByte("{}[]:,;<>", c) >= 0:
+			token := string(c)
+			// angle brackets are an alternative syntax for messages
+			if c == '<' {
+				token = "{"
+			} else if c == '>' {
+				token = "}"
+			}
+			tokens = append(tokens, token)
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(s) && s[j] != c {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				j = len(s) - 1
+			}
+			tokens = append(tokens, s[i:j+1])
+			i = j + 1
+		default:
+			j := i
+			for j < len(s) && strings.IndexByte("{}[]:,;<> \t\n\r\"'", s[j]) < 0 {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		}
+	}
+
+	return tokens
+}
+
+func isString(token string) bool {
+	return strings.HasPrefix(token, `"`) || strings.HasPrefix(token, `'`)
+}
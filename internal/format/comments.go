@@ -0,0 +1,72 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// verifyComments makes sure no comment was dropped during formatting.
+// The parser does not keep comments placed inside statements,
+// e.g. in the middle of an option value
+func verifyComments(original, formatted string) error {
+	counts := map[string]int{}
+	for _, comment := range scanComments(original) {
+		counts[comment]++
+	}
+	for _, comment := range scanComments(formatted) {
+		counts[comment]--
+	}
+
+	for comment, count := range counts {
+		if count > 0 {
+			return fmt.Errorf("comment %q cannot be preserved, move it out of the statement", comment)
+		}
+	}
+
+	return nil
+}
+
+// scanComments returns the comments of the proto file content
+// with the whitespace normalized
+func scanComments(content string) []string {
+	var comments []string
+
+	for i := 0; i < len(content); {
+		switch {
+		case content[i] == '"' || content[i] == '\'':
+			quote := content[i]
+			i++
+			for i < len(content) && content[i] != quote && content[i] != '\n' {
+				if content[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+		case strings.HasPrefix(content[i:], "//"):
+			end := strings.IndexByte(content[i:], '\n')
+			if end < 0 {
+				end = len(content) - i
+			}
+			comments = append(comments, normalizeComment(content[i:i+end]))
+			i += end
+		case strings.HasPrefix(content[i:], "/*"):
+			end := strings.Index(content[i+2:], "*/")
+			if end < 0 {
+				end = len(content) - i - 2
+			} else {
+				end += 2
+			}
+			comments = append(comments, normalizeComment(content[i:i+2+end]))
+			i += 2 + end
+		default:
+			i++
+		}
+	}
+
+	return comments
+}
+
+func normalizeComment(comment string) string {
+	return strings.Join(strings.Fields(comment), " ")
+}
@@ -0,0 +1,447 @@
+package format
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+const (
+	indentUnit    = "  "
+	maxLineLength = 100
+)
+
+// Format rewrites the proto file content into the canonical style:
+//   - two spaces indentation and one statement per line
+//   - syntax, package, sorted imports and sorted options go first
+//   - options go first in the bodies, standard ones before custom ones
+//   - long field declarations have their options on separate lines
+//   - comments are kept with the statements they belong to
+//   - a single blank line is kept where the original had blank lines
+func Format(filename, content string) (string, error) {
+	parsed, err := protoparser.Parse(
+		strings.NewReader(content),
+		protoparser.WithFilename(filename),
+		protoparser.WithBodyIncludingComments(true),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+
+	p := &printer{}
+	p.printProto(parsed)
+	formatted := p.out.String()
+
+	err = verifyComments(content, formatted)
+	if err != nil {
+		return "", fmt.Errorf("failed to format %s: %w", filename, err)
+	}
+
+	return formatted, nil
+}
+
+type printer struct {
+	out strings.Builder
+}
+
+func (p *printer) line(indent, s string) {
+	p.out.WriteString(strings.TrimRight(indent+s, " "))
+	p.out.WriteString("\n")
+}
+
+func (p *printer) blank() {
+	p.out.WriteString("\n")
+}
+
+func (p *printer) comments(indent string, comments []*parser.Comment) {
+	for _, comment := range comments {
+		p.comment(indent, comment)
+	}
+}
+
+// leading prints the comments placed before the statement and keeps
+// a blank line between them if the original had one, e.g. after a license header
+func (p *printer) leading(indent string, comments []*parser.Comment, line int) {
+	p.comments(indent, comments)
+	if len(comments) > 0 && line-comments[len(comments)-1].Meta.LastPos.Line > 1 {
+		p.blank()
+	}
+}
+
+func (p *printer) comment(indent string, comment *parser.Comment) {
+	lines := strings.Split(comment.Raw, "\n")
+	p.line(indent, strings.TrimSpace(lines[0]))
+	for _, line := range lines[1:] {
+		// keep the block comment layout
+		p.out.WriteString(strings.TrimRight(line, " \t"))
+		p.out.WriteString("\n")
+	}
+}
+
+// statement prints a statement followed by the inline comment
+func (p *printer) statement(indent, s string, inline *parser.Comment) {
+	if inline != nil {
+		s += " " + inline.Raw
+	}
+	p.line(indent, s)
+}
+
+func (p *printer) printProto(proto *parser.Proto) {
+	var (
+		packages []*parser.Package
+		imports  []*parser.Import
+		options  []*parser.Option
+		body     []parser.Visitee
+		trailing []*parser.Comment
+	)
+
+	for _, visitee := range proto.ProtoBody {
+		switch v := visitee.(type) {
+		case *parser.Package:
+			packages = append(packages, v)
+		case *parser.Import:
+			imports = append(imports, v)
+		case *parser.Option:
+			options = append(options, v)
+		case *parser.Comment:
+			trailing = append(trailing, v)
+		case *parser.EmptyStatement:
+			if v.InlineComment != nil {
+				trailing = append(trailing, v.InlineComment)
+			}
+		default:
+			body = append(body, visitee)
+		}
+	}
+
+	sections := 0
+	section := func() {
+		if sections > 0 {
+			p.blank()
+		}
+		sections++
+	}
+
+	if proto.Syntax != nil {
+		section()
+		p.leading("", proto.Syntax.Comments, proto.Syntax.Meta.Pos.Line)
+		p.statement("", fmt.Sprintf(`syntax = "%s";`, proto.Syntax.ProtobufVersion), proto.Syntax.InlineComment)
+	}
+
+	for _, pkg := range packages {
+		section()
+		p.leading("", pkg.Comments, pkg.Meta.Pos.Line)
+		p.statement("", fmt.Sprintf("package %s;", pkg.Name), pkg.InlineComment)
+	}
+
+	if len(imports) > 0 {
+		section()
+		sort.SliceStable(imports, func(i, j int) bool {
+			return importPath(imports[i]) < importPath(imports[j])
+		})
+		for _, imported := range imports {
+			p.leading("", imported.Comments, imported.Meta.Pos.Line)
+			p.statement("", fmt.Sprintf("import %s%q;", importModifier(imported), importPath(imported)), imported.InlineComment)
+		}
+	}
+
+	if len(options) > 0 {
+		section()
+		p.printOptions("", options)
+	}
+
+	for _, visitee := range body {
+		section()
+		p.printVisitee("", visitee)
+	}
+
+	if len(trailing) > 0 {
+		section()
+		p.comments("", trailing)
+	}
+}
+
+// printBody prints the statements of a message, enum, service or extend body
+// with options first and the blank lines of the original body preserved
+func (p *printer) printBody(indent string, body []parser.Visitee) {
+	var options []*parser.Option
+	var rest []parser.Visitee
+
+	for _, visitee := range body {
+		if option, ok := visitee.(*parser.Option); ok {
+			options = append(options, option)
+		} else {
+			rest = append(rest, visitee)
+		}
+	}
+
+	p.printOptions(indent, options)
+
+	previousEnd := 0
+	if len(options) > 0 && len(rest) > 0 {
+		p.blank()
+	}
+
+	for i, visitee := range rest {
+		start, end := lines(visitee)
+		if i > 0 && previousEnd > 0 && start-previousEnd > 1 {
+			p.blank()
+		}
+		p.printVisitee(indent, visitee)
+		previousEnd = end
+	}
+}
+
+// gap keeps a blank line between the statements if the original had one
+func (p *printer) gap(previousEnd int, comments []*parser.Comment, line int) {
+	if len(comments) > 0 {
+		line = comments[0].Meta.Pos.Line
+	}
+	if line-previousEnd > 1 {
+		p.blank()
+	}
+}
+
+func (p *printer) printOptions(indent string, options []*parser.Option) {
+	// standard options go before the custom ones
+	sort.SliceStable(options, func(i, j int) bool {
+		iCustom := strings.HasPrefix(options[i].OptionName, "(")
+		jCustom := strings.HasPrefix(options[j].OptionName, "(")
+		if iCustom != jCustom {
+			return !iCustom
+		}
+		return options[i].OptionName < options[j].OptionName
+	})
+
+	for _, option := range options {
+		p.leading(indent, option.Comments, option.Meta.Pos.Line)
+		p.statement(indent, fmt.Sprintf("option %s = %s;",
+			option.OptionName, formatConstant(option.Constant, indent, true)), option.InlineComment)
+	}
+}
+
+func (p *printer) printVisitee(indent string, visitee parser.Visitee) {
+	switch v := visitee.(type) {
+	case *parser.Message:
+		p.leading(indent, v.Comments, v.Meta.Pos.Line)
+		p.block(indent, "message "+v.MessageName, v.InlineCommentBehindLeftCurly, v.MessageBody, v.InlineComment)
+	case *parser.Enum:
+		p.leading(indent, v.Comments, v.Meta.Pos.Line)
+		p.block(indent, "enum "+v.EnumName, v.InlineCommentBehindLeftCurly, v.EnumBody, v.InlineComment)
+	case *parser.Service:
+		p.leading(indent, v.Comments, v.Meta.Pos.Line)
+		p.block(indent, "service "+v.ServiceName, v.InlineCommentBehindLeftCurly, v.ServiceBody, v.InlineComment)
+	case *parser.Extend:
+		p.leading(indent, v.Comments, v.Meta.Pos.Line)
+		p.block(indent, "extend "+v.MessageType, v.InlineCommentBehindLeftCurly, v.ExtendBody, v.InlineComment)
+	case *parser.GroupField:
+		p.leading(indent, v.Comments, v.Meta.Pos.Line)
+		header := fmt.Sprintf("%sgroup %s = %s", label(v.IsRepeated, v.IsRequired, v.IsOptional), v.GroupName, v.FieldNumber)
+		p.block(indent, header, v.InlineCommentBehindLeftCurly, v.MessageBody, v.InlineComment)
+	case *parser.Oneof:
+		p.leading(indent, v.Comments, v.Meta.Pos.Line)
+		p.openBlock(indent, "oneof "+v.OneofName, v.InlineCommentBehindLeftCurly)
+		p.printOptions(indent+indentUnit, v.Options)
+		for i, field := range v.OneofFields {
+			if i > 0 {
+				p.gap(v.OneofFields[i-1].Meta.LastPos.Line, field.Comments, field.Meta.Pos.Line)
+			}
+			p.leading(indent+indentUnit, field.Comments, field.Meta.Pos.Line)
+			p.field(indent+indentUnit, fmt.Sprintf("%s %s = %s",
+				field.Type, field.FieldName, field.FieldNumber), field.FieldOptions, field.InlineComment)
+		}
+		p.statement(indent, "}", v.InlineComment)
+	case *parser.Field:
+		p.leading(indent, v.Comments, v.Meta.Pos.Line)
+		p.field(indent, fmt.Sprintf("%s%s %s = %s",
+			label(v.IsRepeated, v.IsRequired, v.IsOptional), v.Type, v.FieldName, v.FieldNumber), v.FieldOptions, v.InlineComment)
+	case *parser.MapField:
+		p.leading(indent, v.Comments, v.Meta.Pos.Line)
+		p.field(indent, fmt.Sprintf("map<%s, %s> %s = %s",
+			v.KeyType, v.Type, v.MapName, v.FieldNumber), v.FieldOptions, v.InlineComment)
+	case *parser.EnumField:
+		var options []*parser.FieldOption
+		for _, option := range v.EnumValueOptions {
+			options = append(options, &parser.FieldOption{OptionName: option.OptionName, Constant: option.Constant})
+		}
+		p.leading(indent, v.Comments, v.Meta.Pos.Line)
+		p.field(indent, fmt.Sprintf("%s = %s", v.Ident, v.Number), options, v.InlineComment)
+	case *parser.Reserved:
+		p.leading(indent, v.Comments, v.Meta.Pos.Line)
+		p.statement(indent, fmt.Sprintf("reserved %s;", reserved(v.Ranges, v.FieldNames)), v.InlineComment)
+	case *parser.Extensions:
+		p.leading(indent, v.Comments, v.Meta.Pos.Line)
+		p.statement(indent, fmt.Sprintf("extensions %s;", reserved(v.Ranges, nil)), v.InlineComment)
+	case *parser.RPC:
+		p.leading(indent, v.Comments, v.Meta.Pos.Line)
+		signature := fmt.Sprintf("rpc %s(%s%s) returns (%s%s)", v.RPCName,
+			stream(v.RPCRequest.IsStream), v.RPCRequest.MessageType,
+			stream(v.RPCResponse.IsStream), v.RPCResponse.MessageType)
+		if len(v.Options) == 0 && v.InlineCommentBehindLeftCurly == nil {
+			p.statement(indent, signature+";", v.InlineComment)
+			return
+		}
+		p.openBlock(indent, signature, v.InlineCommentBehindLeftCurly)
+		p.printOptions(indent+indentUnit, v.Options)
+		p.statement(indent, "}", v.InlineComment)
+	case *parser.Option:
+		p.printOptions(indent, []*parser.Option{v})
+	case *parser.Comment:
+		p.comment(indent, v)
+	case *parser.EmptyStatement:
+		if v.InlineComment != nil {
+			p.comment(indent, v.InlineComment)
+		}
+	}
+}
+
+func (p *printer) openBlock(indent, header string, inlineLeftCurly *parser.Comment) {
+	p.statement(indent, header+" {", inlineLeftCurly)
+}
+
+func (p *printer) block(indent, header string, inlineLeftCurly *parser.Comment, body []parser.Visitee, inline *parser.Comment) {
+	if len(body) == 0 && inlineLeftCurly == nil {
+		p.statement(indent, header+" {}", inline)
+		return
+	}
+
+	p.openBlock(indent, header, inlineLeftCurly)
+	p.printBody(indent+indentUnit, body)
+	p.statement(indent, "}", inline)
+}
+
+// lines returns the first and the last lines of the statement including its comments
+func lines(visitee parser.Visitee) (int, int) {
+	var start, end int
+	var comments []*parser.Comment
+	var inline *parser.Comment
+
+	switch v := visitee.(type) {
+	case *parser.Message:
+		start, end, comments, inline = v.Meta.Pos.Line, v.Meta.LastPos.Line, v.Comments, v.InlineComment
+	case *parser.Enum:
+		start, end, comments, inline = v.Meta.Pos.Line, v.Meta.LastPos.Line, v.Comments, v.InlineComment
+	case *parser.Service:
+		start, end, comments, inline = v.Meta.Pos.Line, v.Meta.LastPos.Line, v.Comments, v.InlineComment
+	case *parser.Extend:
+		start, end, comments, inline = v.Meta.Pos.Line, v.Meta.LastPos.Line, v.Comments, v.InlineComment
+	case *parser.GroupField:
+		start, end, comments, inline = v.Meta.Pos.Line, v.Meta.LastPos.Line, v.Comments, v.InlineComment
+	case *parser.Oneof:
+		start, end, comments, inline = v.Meta.Pos.Line, v.Meta.LastPos.Line, v.Comments, v.InlineComment
+	case *parser.Field:
+		start, end, comments, inline = v.Meta.Pos.Line, v.Meta.LastPos.Line, v.Comments, v.InlineComment
+	case *parser.MapField:
+		start, end, comments, inline = v.Meta.Pos.Line, v.Meta.LastPos.Line, v.Comments, v.InlineComment
+	case *parser.EnumField:
+		start, end, comments, inline = v.Meta.Pos.Line, v.Meta.LastPos.Line, v.Comments, v.InlineComment
+	case *parser.Reserved:
+		start, end, comments, inline = v.Meta.Pos.Line, v.Meta.LastPos.Line, v.Comments, v.InlineComment
+	case *parser.Extensions:
+		start, end, comments, inline = v.Meta.Pos.Line, v.Meta.LastPos.Line, v.Comments, v.InlineComment
+	case *parser.RPC:
+		start, end, comments, inline = v.Meta.Pos.Line, v.Meta.LastPos.Line, v.Comments, v.InlineComment
+	case *parser.Comment:
+		start, end = v.Meta.Pos.Line, v.Meta.LastPos.Line
+	}
+
+	if len(comments) > 0 {
+		start = comments[0].Meta.Pos.Line
+	}
+	if inline != nil && inline.Meta.LastPos.Line > end {
+		end = inline.Meta.LastPos.Line
+	}
+	if end < start {
+		end = start
+	}
+
+	return start, end
+}
+
+// formatConstant formats the option value
+func formatConstant(constant, indent string, multiline bool) string {
+	if strings.HasPrefix(constant, "{") {
+		return formatAggregate(constant, indent, multiline)
+	}
+	return constant
+}
+
+// field prints a field or an enum value declaration with its compact options.
+// The options are placed on separate lines if the declaration is too long
+func (p *printer) field(indent, declaration string, options []*parser.FieldOption, inline *parser.Comment) {
+	if len(options) == 0 {
+		p.statement(indent, declaration+";", inline)
+		return
+	}
+
+	var formatted []string
+	for _, option := range options {
+		formatted = append(formatted, fmt.Sprintf("%s = %s", option.OptionName, formatConstant(option.Constant, indent, false)))
+	}
+
+	singleLine := fmt.Sprintf("%s [%s];", declaration, strings.Join(formatted, ", "))
+	if len(indent)+len(singleLine) <= maxLineLength {
+		p.statement(indent, singleLine, inline)
+		return
+	}
+
+	p.line(indent, declaration+" [")
+	for i, option := range formatted {
+		if i < len(formatted)-1 {
+			option += ","
+		}
+		p.line(indent+indentUnit, option)
+	}
+	p.statement(indent, "];", inline)
+}
+
+func reserved(ranges []*parser.Range, names []string) string {
+	var values []string
+	for _, r := range ranges {
+		if r.End == "" {
+			values = append(values, r.Begin)
+		} else {
+			values = append(values, r.Begin+" to "+r.End)
+		}
+	}
+	values = append(values, names...)
+	return strings.Join(values, ", ")
+}
+
+func label(repeated, required, optional bool) string {
+	switch {
+	case repeated:
+		return "repeated "
+	case required:
+		return "required "
+	case optional:
+		return "optional "
+	default:
+		return ""
+	}
+}
+
+func stream(isStream bool) string {
+	if isStream {
+		return "stream "
+	}
+	return ""
+}
+
+func importPath(imported *parser.Import) string {
+	return strings.Trim(imported.Location, `"'`)
+}
+
+func importModifier(imported *parser.Import) string {
+	switch imported.Modifier {
+	case parser.ImportModifierPublic:
+		return "public "
+	case parser.ImportModifierWeak:
+		return "weak "
+	default:
+		return ""
+	}
+}
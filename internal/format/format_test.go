@@ -0,0 +1,141 @@
+package format
+
+import "testing"
+
+const (
+	unformattedProtoFile = `// Copyright header
+
+syntax = "proto3";
+package acme.v1;
+import "google/protobuf/timestamp.proto";
+import "acme/v1/money.proto";
+option java_package = "com.acme.v1";
+option go_package = "github.com/acme/api/v1;v1";
+option (acme.file) = {name:"x" tags:["a","b"]};
+
+// Payments processes payments.
+service Payments {
+    rpc Pay ( PayRequest ) returns ( stream PayResponse ) {
+        option (google.api.http) = { post: "/v1/pay" body: "*" };
+    }
+}
+
+message PayRequest { // request
+    // id of the payment
+    string id = 1;   // trailing
+
+
+    map<string,Money> amounts = 2 [deprecated=true];
+  oneof method {
+    string card = 3;
+    string wallet = 4;
+  }
+  reserved 5 to 10, 12;
+  option deprecated = true;
+}
+
+message PayResponse {}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_PAID = 1 [deprecated = true];
+  // end of values
+}
+`
+
+	formattedProtoFile = `// Copyright header
+
+syntax = "proto3";
+
+package acme.v1;
+
+import "acme/v1/money.proto";
+import "google/protobuf/timestamp.proto";
+
+option go_package = "github.com/acme/api/v1;v1";
+option java_package = "com.acme.v1";
+option (acme.file) = {
+  name: "x"
+  tags: ["a", "b"]
+};
+
+// Payments processes payments.
+service Payments {
+  rpc Pay(PayRequest) returns (stream PayResponse) {
+    option (google.api.http) = {
+      post: "/v1/pay"
+      body: "*"
+    };
+  }
+}
+
+message PayRequest { // request
+  option deprecated = true;
+
+  // id of the payment
+  string id = 1; // trailing
+
+  map<string, Money> amounts = 2 [deprecated = true];
+  oneof method {
+    string card = 3;
+    string wallet = 4;
+  }
+  reserved 5 to 10, 12;
+}
+
+message PayResponse {}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_PAID = 1 [deprecated = true];
+  // end of values
+}
+`
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "unformatted",
+			content: unformattedProtoFile,
+			want:    formattedProtoFile,
+		},
+		{
+			name:    "formatted",
+			content: formattedProtoFile,
+			want:    formattedProtoFile,
+		},
+		{
+			name: "comment inside option value",
+			content: `syntax = "proto3";
+option (acme.file) = {
+  // cannot be kept
+  name: "x"
+};
+`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid file",
+			content: `syntax = "proto3"; message {`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Format("acme/v1/payments.proto", tt.content)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Format() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Format() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
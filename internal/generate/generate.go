@@ -0,0 +1,209 @@
+package generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/bufbuild/protocompile/linker"
+	"github.com/pbufio/pbuf-cli/internal/model"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+// target is a group of files generated into the same folder
+type target struct {
+	// files are relative to the include paths
+	files []string
+	// out overrides the plugin output folder when set
+	out string
+	// strip is the prefix removed from the generated file names
+	strip string
+}
+
+// Generate compiles the vendored modules that have `gen_out` set
+// and the exported proto files, and runs every configured plugin on them.
+// The files of a vendored module are generated into its `gen_out` folder,
+// the exported files into the plugin output folder.
+// Vendored modules without `gen_out` are used only to resolve imports.
+func Generate(ctx context.Context, config *model.Config) error {
+	if len(config.Generate) == 0 {
+		return errors.New("no plugins found. see `generate` section in pbuf.yaml reference")
+	}
+
+	var allDirs []string
+	for _, module := range config.Modules {
+		allDirs = append(allDirs, module.VendorDir())
+	}
+	allDirs = append(allDirs, config.Export.Paths...)
+
+	allFiles, err := collectProtoFiles(existingDirs(allDirs))
+	if err != nil {
+		return fmt.Errorf("failed to collect proto files: %w", err)
+	}
+
+	includePaths := deriveIncludePaths(allFiles)
+
+	targets, err := newTargets(config, includePaths)
+	if err != nil {
+		return err
+	}
+
+	if slices.ContainsFunc(targets, func(t *target) bool { return t.out != "" && len(t.files) > 0 }) {
+		for _, plugin := range config.Generate {
+			if err := checkPaths(plugin); err != nil {
+				return fmt.Errorf("plugin %s: %w", plugin.Name, err)
+			}
+		}
+	}
+
+	var filesToGenerate []string
+	for _, t := range targets {
+		for _, file := range t.files {
+			if !slices.Contains(filesToGenerate, file) {
+				filesToGenerate = append(filesToGenerate, file)
+			}
+		}
+	}
+
+	if len(filesToGenerate) == 0 {
+		return errors.New("no proto files to generate. run `pbuf vendor` first")
+	}
+
+	log.Printf("compiling %d proto files. include paths: %v", len(filesToGenerate), includePaths)
+
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			ImportPaths: includePaths,
+		}),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+
+	compiled, err := compiler.Compile(ctx, filesToGenerate...)
+	if err != nil {
+		return fmt.Errorf("failed to compile proto files: %w", err)
+	}
+
+	protoFiles := toFileDescriptorProtos(compiled)
+
+	for _, plugin := range config.Generate {
+		for _, t := range targets {
+			if len(t.files) == 0 {
+				continue
+			}
+
+			out := t.out
+			if out == "" {
+				out = plugin.Out
+			}
+
+			request := &pluginpb.CodeGeneratorRequest{
+				FileToGenerate: t.files,
+				ProtoFile:      protoFiles,
+			}
+
+			err := runPlugin(ctx, plugin, request, out, t.strip)
+			if err != nil {
+				return fmt.Errorf("plugin %s failed: %w", plugin.Name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// newTargets groups the files to generate: one group per vendored module with `gen_out`
+// and one for the exported files
+func newTargets(config *model.Config, includePaths []string) ([]*target, error) {
+	var targets []*target
+	for _, module := range config.Modules {
+		if module.GenerateOutputFolder == "" {
+			continue
+		}
+
+		dir := filepath.ToSlash(filepath.Clean(module.VendorDir()))
+		moduleTarget, err := newTarget(existingDirs([]string{dir}), includePaths)
+		if err != nil {
+			return nil, err
+		}
+		moduleTarget.out = module.GenerateOutputFolder
+		moduleTarget.strip = relativeToInclude(dir, includePaths)
+		if slices.Contains(includePaths, dir) {
+			moduleTarget.strip = ""
+		}
+		targets = append(targets, moduleTarget)
+	}
+
+	exportTarget, err := newTarget(existingDirs(config.Export.Paths), includePaths)
+	if err != nil {
+		return nil, err
+	}
+	targets = append(targets, exportTarget)
+
+	return targets, nil
+}
+
+// newTarget collects the proto files of the dirs relative to the include paths
+func newTarget(dirs, includePaths []string) (*target, error) {
+	files, err := collectProtoFiles(dirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect proto files: %w", err)
+	}
+
+	t := &target{}
+	for _, file := range files {
+		t.files = append(t.files, relativeToInclude(file, includePaths))
+	}
+
+	return t, nil
+}
+
+// toFileDescriptorProtos returns the compiled files and all their dependencies
+// in topological order as required by CodeGeneratorRequest
+func toFileDescriptorProtos(files linker.Files) []*descriptorpb.FileDescriptorProto {
+	var result []*descriptorpb.FileDescriptorProto
+	seen := map[string]bool{}
+
+	var visit func(file protoreflect.FileDescriptor)
+	visit = func(file protoreflect.FileDescriptor) {
+		if seen[file.Path()] {
+			return
+		}
+		seen[file.Path()] = true
+
+		imports := file.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			visit(imports.Get(i).FileDescriptor)
+		}
+
+		if res, ok := file.(linker.Result); ok {
+			result = append(result, res.FileDescriptorProto())
+		} else {
+			result = append(result, protodesc.ToFileDescriptorProto(file))
+		}
+	}
+
+	for _, file := range files {
+		visit(file)
+	}
+
+	return result
+}
+
+// existingDirs filters out the folders that are not vendored yet
+func existingDirs(dirs []string) []string {
+	var result []string
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); err == nil {
+			result = append(result, dir)
+		}
+	}
+	return result
+}
@@ -0,0 +1,121 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+// collectProtoFiles walks the dirs and returns all .proto files found
+func collectProtoFiles(dirs []string) ([]string, error) {
+	var result []string
+	seen := map[string]bool{}
+
+	for _, dir := range dirs {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() || !strings.HasSuffix(path, ".proto") {
+				return nil
+			}
+
+			path = filepath.ToSlash(filepath.Clean(path))
+			if !seen[path] {
+				seen[path] = true
+				result = append(result, path)
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(result)
+	return result, nil
+}
+
+// deriveIncludePaths finds the import roots of the given files.
+// A root is the part of the file path left after stripping an import
+// statement that points to this file, e.g. `third_party` for the
+// `third_party/google/api/http.proto` file imported as `google/api/http.proto`.
+// The current folder is always the last root.
+func deriveIncludePaths(files []string) []string {
+	roots := map[string]bool{}
+
+	for _, file := range files {
+		for _, imported := range parseImports(file) {
+			for _, candidate := range files {
+				if candidate == imported {
+					roots["."] = true
+				} else if strings.HasSuffix(candidate, "/"+imported) {
+					roots[strings.TrimSuffix(candidate, "/"+imported)] = true
+				}
+			}
+		}
+	}
+
+	delete(roots, ".")
+
+	var result []string
+	for root := range roots {
+		result = append(result, root)
+	}
+
+	// the longest roots first to resolve the most specific ones
+	sort.Slice(result, func(i, j int) bool {
+		if len(result[i]) != len(result[j]) {
+			return len(result[i]) > len(result[j])
+		}
+		return result[i] < result[j]
+	})
+
+	return append(result, ".")
+}
+
+// relativeToInclude returns the file name relative to the longest matching include path
+func relativeToInclude(file string, includePaths []string) string {
+	for _, include := range includePaths {
+		if include == "." {
+			continue
+		}
+
+		if strings.HasPrefix(file, include+"/") {
+			return strings.TrimPrefix(file, include+"/")
+		}
+	}
+
+	return file
+}
+
+// parseImports returns the imports of the file
+// files that cannot be parsed are reported later by the compiler
+func parseImports(file string) []string {
+	reader, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer reader.Close()
+
+	parsed, err := protoparser.Parse(reader, protoparser.WithFilename(file))
+	if err != nil {
+		return nil
+	}
+
+	var result []string
+	for _, visitee := range parsed.ProtoBody {
+		if imported, ok := visitee.(*parser.Import); ok {
+			result = append(result, strings.Trim(imported.Location, `"'`))
+		}
+	}
+
+	return result
+}
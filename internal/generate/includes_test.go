@@ -0,0 +1,143 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pbufio/pbuf-cli/internal/model"
+)
+
+func TestDeriveIncludePaths(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name: "vendored module",
+			files: map[string]string{
+				"third_party/pbuf-registry/v1/entities.proto": `syntax = "proto3";`,
+				"third_party/pbuf-registry/v1/registry.proto": `syntax = "proto3";
+import "pbuf-registry/v1/entities.proto";`,
+			},
+			want: []string{"third_party", "."},
+		},
+		{
+			name: "nested vendored modules",
+			files: map[string]string{
+				"third_party/google/api/http.proto": `syntax = "proto3";`,
+				"third_party/google/api/annotations.proto": `syntax = "proto3";
+import "google/api/http.proto";`,
+				"proto/vendor/acme/v1/acme.proto": `syntax = "proto3";`,
+				"api/v1/service.proto": `syntax = "proto3";
+import "acme/v1/acme.proto";
+import "google/api/annotations.proto";`,
+			},
+			want: []string{"proto/vendor", "third_party", "."},
+		},
+		{
+			name: "current folder",
+			files: map[string]string{
+				"api/v1/entities.proto": `syntax = "proto3";`,
+				"api/v1/service.proto": `syntax = "proto3";
+import "api/v1/entities.proto";`,
+			},
+			want: []string{"."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+
+			var files []string
+			for file, content := range tt.files {
+				err := os.MkdirAll(filepath.Dir(file), os.ModePerm)
+				if err != nil {
+					t.Fatal(err)
+				}
+				err = os.WriteFile(file, []byte(content), 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+				files = append(files, file)
+			}
+
+			got := deriveIncludePaths(files)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("deriveIncludePaths() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTargets(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	files := map[string]string{
+		"third_party/pbuf-registry/v1/entities.proto": `syntax = "proto3";`,
+		"third_party/pbuf-registry/v1/registry.proto": `syntax = "proto3";
+import "pbuf-registry/v1/entities.proto";`,
+		"third_party/google/api/http.proto": `syntax = "proto3";`,
+		"api/v1/service.proto": `syntax = "proto3";
+import "pbuf-registry/v1/registry.proto";`,
+	}
+	for file, content := range files {
+		if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := &model.Config{
+		Export: model.Export{Paths: []string{"api"}},
+		Modules: []*model.Module{
+			{Name: "pbufio/pbuf-registry", OutputFolder: "third_party/pbuf-registry", GenerateOutputFolder: "gen/pbuf-registry"},
+			{Name: "googleapis", OutputFolder: "third_party/google/api"},
+		},
+	}
+
+	targets, err := newTargets(config, []string{"third_party", "."})
+	if err != nil {
+		t.Fatalf("newTargets() error = %v", err)
+	}
+
+	want := []*target{
+		{
+			files: []string{"pbuf-registry/v1/entities.proto", "pbuf-registry/v1/registry.proto"},
+			out:   "gen/pbuf-registry",
+			strip: "pbuf-registry",
+		},
+		{
+			files: []string{"api/v1/service.proto"},
+		},
+	}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("newTargets() got = %+v, want %+v", targets, want)
+	}
+}
+
+func TestCheckPaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		opt     string
+		wantErr bool
+	}{
+		{name: "no options"},
+		{name: "source relative", opt: "paths=source_relative"},
+		{name: "other options", opt: "require_unimplemented_servers=false,paths=source_relative"},
+		{name: "import", opt: "paths=import", wantErr: true},
+		{name: "import with module", opt: "module=github.com/acme/api,paths=import", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPaths(&model.Plugin{Name: "go", Opt: tt.opt})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkPaths() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package generate
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pbufio/pbuf-cli/internal/model"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+const pluginPrefix = "protoc-gen-"
+
+// runPlugin sends the CodeGeneratorRequest to the plugin via stdin
+// and writes the files from the CodeGeneratorResponse to the output folder.
+// The strip prefix is removed from the generated file names
+func runPlugin(ctx context.Context, plugin *model.Plugin, request *pluginpb.CodeGeneratorRequest, out, strip string) error {
+	binary := plugin.Path
+	if binary == "" {
+		if plugin.Name == "" {
+			return errors.New("plugin name or path is required")
+		}
+		binary = pluginPrefix + plugin.Name
+	}
+
+	if out == "" {
+		out = "."
+	}
+
+	pluginRequest := proto.Clone(request).(*pluginpb.CodeGeneratorRequest)
+	if plugin.Opt != "" {
+		pluginRequest.Parameter = proto.String(plugin.Opt)
+	}
+
+	input, err := proto.Marshal(pluginRequest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var stdout bytes.Buffer
+	command := exec.CommandContext(ctx, binary)
+	command.Stdin = bytes.NewReader(input)
+	command.Stdout = &stdout
+	command.Stderr = os.Stderr
+
+	log.Printf("running plugin %s. out: %s", binary, out)
+
+	err = command.Run()
+	if err != nil {
+		return err
+	}
+
+	response := &pluginpb.CodeGeneratorResponse{}
+	err = proto.Unmarshal(stdout.Bytes(), response)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if response.Error != nil {
+		return errors.New(response.GetError())
+	}
+
+	for _, file := range response.File {
+		if file.GetInsertionPoint() != "" {
+			return fmt.Errorf("insertion points are not supported: %s", file.GetName())
+		}
+
+		name := file.GetName()
+		if strip != "" {
+			if !strings.HasPrefix(name, strip+"/") {
+				return fmt.Errorf("generated file %s is outside of %s, use paths=source_relative", name, strip)
+			}
+			name = strings.TrimPrefix(name, strip+"/")
+		}
+
+		outputPath := filepath.Join(out, name)
+
+		err = os.MkdirAll(filepath.Dir(outputPath), os.ModePerm)
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(outputPath, []byte(file.GetContent()), 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Printf("successfully generated %d files. plugin: %s", len(response.File), binary)
+
+	return nil
+}
+
+// checkPaths verifies the plugin names the files after the proto files, as the files of a module
+// are written relative to its `gen_out` folder. Go plugins need paths=source_relative for that
+func checkPaths(plugin *model.Plugin) error {
+	for _, parameter := range strings.Split(plugin.Opt, ",") {
+		value, ok := strings.CutPrefix(strings.TrimSpace(parameter), "paths=")
+		if ok && value != "source_relative" {
+			return fmt.Errorf("paths=%s is not supported with gen_out, use paths=source_relative", value)
+		}
+	}
+	return nil
+}
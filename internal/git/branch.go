@@ -0,0 +1,35 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+var invalidTagChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// CurrentBranch returns the branch checked out in the repository containing the dir
+func CurrentBranch(dir string) (string, error) {
+	repository, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return "", fmt.Errorf("failed to open git repository: %w", err)
+	}
+
+	head, err := repository.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	if !head.Name().IsBranch() {
+		return "", fmt.Errorf("HEAD is detached. check out a branch")
+	}
+
+	return head.Name().Short(), nil
+}
+
+// DraftTag converts the branch name to a tag, e.g. `feature/new-api` to `feature-new-api`
+func DraftTag(branch string) string {
+	return strings.Trim(invalidTagChars.ReplaceAllString(branch, "-"), "-.")
+}
@@ -0,0 +1,23 @@
+package git
+
+import "testing"
+
+func TestDraftTag(t *testing.T) {
+	tests := []struct {
+		name   string
+		branch string
+		want   string
+	}{
+		{name: "plain branch", branch: "main", want: "main"},
+		{name: "branch with slashes", branch: "feature/new-api", want: "feature-new-api"},
+		{name: "branch with special chars", branch: "fix/JIRA-1 #2", want: "fix-JIRA-1-2"},
+		{name: "branch with trailing slash", branch: "/wip/", want: "wip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DraftTag(tt.branch); got != tt.want {
+				t.Errorf("DraftTag() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/pbufio/pbuf-cli/internal/model"
+	"github.com/pbufio/pbuf-cli/internal/schema"
+)
+
+const (
+	FormatText   = "text"
+	FormatJSON   = "json"
+	FormatGitHub = "github"
+)
+
+// Issue is a rule violation found in a file
+type Issue struct {
+	Rule     string `json:"rule"`
+	Filename string `json:"filename"`
+	Line     int    `json:"line"`
+	Message  string `json:"message"`
+}
+
+func (i *Issue) String() string {
+	return fmt.Sprintf("%s:%d: %s %s", i.Filename, i.Line, i.Rule, i.Message)
+}
+
+// RuleNames returns the names of all available rules
+func RuleNames() []string {
+	var names []string
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lint applies the configured rules to the files
+func Lint(files []*schema.File, config model.Lint) ([]*Issue, error) {
+	enabled, err := enabledRules(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []*Issue
+	for _, file := range files {
+		for _, name := range enabled {
+			if isIgnored(config.Ignore[name], file.Name) {
+				continue
+			}
+
+			rules[name](file, func(line int, format string, args ...any) {
+				issues = append(issues, &Issue{
+					Rule:     name,
+					Filename: file.Name,
+					Line:     line,
+					Message:  fmt.Sprintf(format, args...),
+				})
+			})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Filename != issues[j].Filename {
+			return issues[i].Filename < issues[j].Filename
+		}
+		return issues[i].Line < issues[j].Line
+	})
+
+	return issues, nil
+}
+
+func enabledRules(config model.Lint) ([]string, error) {
+	names := config.Rules
+	if len(names) == 0 {
+		names = RuleNames()
+	}
+
+	for _, name := range append(names, config.Except...) {
+		if _, ok := rules[name]; !ok {
+			return nil, fmt.Errorf("unknown lint rule %q", name)
+		}
+	}
+
+	var result []string
+	for _, name := range names {
+		if !contains(config.Except, name) {
+			result = append(result, name)
+		}
+	}
+	return result, nil
+}
+
+// isIgnored checks whether the file matches one of the ignored paths.
+// A path is either a folder, a file or a glob pattern
+func isIgnored(ignored []string, filename string) bool {
+	for _, pattern := range ignored {
+		pattern = strings.TrimSuffix(path.Clean(pattern), "/")
+		if filename == pattern || strings.HasPrefix(filename, pattern+"/") {
+			return true
+		}
+		if matched, _ := path.Match(pattern, filename); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Write prints the issues in the given format
+func Write(w io.Writer, issues []*Issue, format string) error {
+	switch format {
+	case "", FormatText:
+		for _, issue := range issues {
+			if _, err := fmt.Fprintln(w, issue.String()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatJSON:
+		if issues == nil {
+			issues = []*Issue{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(issues)
+	case FormatGitHub:
+		for _, issue := range issues {
+			// see https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#setting-an-error-message
+			if _, err := fmt.Fprintf(w, "::error file=%s,line=%d,title=%s::%s\n",
+				issue.Filename, issue.Line, issue.Rule, issue.Message); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q (expected text|json|github)", format)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/model"
+	"github.com/pbufio/pbuf-cli/internal/schema"
+)
+
+const (
+	validProtoFile = `
+syntax = "proto3";
+package acme.payments.v1;
+
+// Payments processes payments.
+service Payments {
+  rpc Pay(PayRequest) returns (PayResponse);
+}
+
+message PayRequest {
+  string payment_id = 1;
+  Status status = 2;
+  oneof amount {
+    int64 units = 3;
+  }
+}
+
+message PayResponse {}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_PAID = 1;
+}
+`
+
+	invalidProtoFile = `
+syntax = "proto2";
+package acme.payments;
+
+service payments {
+  rpc pay(pay_request) returns (pay_request);
+}
+
+message pay_request {
+  required string PaymentID = 1;
+}
+
+enum Status {
+  UNKNOWN = 0;
+  paid = 1;
+}
+`
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		config   model.Lint
+		want     []string
+	}{
+		{
+			name:     "valid file",
+			filename: "acme/payments/v1/payments.proto",
+			content:  validProtoFile,
+			want:     nil,
+		},
+		{
+			name:     "directory mismatch",
+			filename: "proto/payments.proto",
+			content:  validProtoFile,
+			want:     []string{RulePackageDirectoryMatch},
+		},
+		{
+			name:     "invalid file",
+			filename: "acme/payments/payments.proto",
+			content:  invalidProtoFile,
+			want: []string{
+				RulePackageVersionSuffix,
+				RuleServiceComment,
+				RuleServicePascalCase,
+				RuleRPCPascalCase,
+				RuleMessagePascalCase,
+				RuleFieldLowerSnakeCase,
+				RuleFieldNoRequired,
+				RuleEnumZeroValueSuffix,
+				RuleEnumValueUpperSnakeCase,
+			},
+		},
+		{
+			name:     "ignored and excepted rules",
+			filename: "acme/payments/payments.proto",
+			content:  invalidProtoFile,
+			config: model.Lint{
+				Rules:  []string{RuleFieldNoRequired, RuleEnumZeroValueSuffix, RuleServiceComment},
+				Except: []string{RuleServiceComment},
+				Ignore: map[string][]string{
+					RuleFieldNoRequired: {"acme/payments"},
+				},
+			},
+			want: []string{RuleEnumZeroValueSuffix},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := schema.ParseAll([]*v1.ProtoFile{{Filename: tt.filename, Content: tt.content}})
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+
+			issues, err := Lint(files, tt.config)
+			if err != nil {
+				t.Fatalf("Lint() error = %v", err)
+			}
+
+			var got []string
+			for _, issue := range issues {
+				got = append(got, issue.Rule)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lint() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package lint
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pbufio/pbuf-cli/internal/schema"
+)
+
+const (
+	RulePackageDefined          = "PACKAGE_DEFINED"
+	RulePackageLowerSnakeCase   = "PACKAGE_LOWER_SNAKE_CASE"
+	RulePackageVersionSuffix    = "PACKAGE_VERSION_SUFFIX"
+	RulePackageDirectoryMatch   = "PACKAGE_DIRECTORY_MATCH"
+	RuleMessagePascalCase       = "MESSAGE_PASCAL_CASE"
+	RuleFieldLowerSnakeCase     = "FIELD_LOWER_SNAKE_CASE"
+	RuleFieldNoRequired         = "FIELD_NO_REQUIRED"
+	RuleOneofLowerSnakeCase     = "ONEOF_LOWER_SNAKE_CASE"
+	RuleEnumPascalCase          = "ENUM_PASCAL_CASE"
+	RuleEnumValueUpperSnakeCase = "ENUM_VALUE_UPPER_SNAKE_CASE"
+	RuleEnumZeroValueSuffix     = "ENUM_ZERO_VALUE_SUFFIX"
+	RuleServicePascalCase       = "SERVICE_PASCAL_CASE"
+	RuleServiceComment          = "SERVICE_COMMENT"
+	RuleRPCPascalCase           = "RPC_PASCAL_CASE"
+)
+
+const enumZeroValueSuffix = "_UNSPECIFIED"
+
+var (
+	pascalCase     = regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`)
+	lowerSnakeCase = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+	upperSnakeCase = regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`)
+	packageName    = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)*$`)
+	packageVersion = regexp.MustCompile(`^v\d+((alpha|beta)\d*)?$`)
+)
+
+// rule checks a single file and reports the issues
+type rule func(file *schema.File, report reportFunc)
+
+type reportFunc func(line int, format string, args ...any)
+
+// rules contains all available rules by their names
+var rules = map[string]rule{
+	RulePackageDefined: func(file *schema.File, report reportFunc) {
+		if file.Package == "" {
+			report(1, "file does not declare a package")
+		}
+	},
+	RulePackageLowerSnakeCase: func(file *schema.File, report reportFunc) {
+		if file.Package != "" && !packageName.MatchString(file.Package) {
+			report(packageLine(file), "package %q should be lower_snake.case", file.Package)
+		}
+	},
+	RulePackageVersionSuffix: func(file *schema.File, report reportFunc) {
+		if file.Package == "" {
+			return
+		}
+		parts := strings.Split(file.Package, ".")
+		if !packageVersion.MatchString(parts[len(parts)-1]) {
+			report(packageLine(file), "package %q should end with a version, e.g. %s.v1", file.Package, file.Package)
+		}
+	},
+	RulePackageDirectoryMatch: func(file *schema.File, report reportFunc) {
+		if file.Package == "" {
+			return
+		}
+		expected := strings.ReplaceAll(file.Package, ".", "/")
+		dir := path.Dir(file.Name)
+		if dir != expected && !strings.HasSuffix(dir, "/"+expected) {
+			report(packageLine(file), "package %q should be placed in a %q directory, found in %q", file.Package, expected, dir)
+		}
+	},
+	RuleMessagePascalCase: func(file *schema.File, report reportFunc) {
+		walkMessages(file.Messages, func(message *schema.Message) {
+			if !pascalCase.MatchString(message.Name) {
+				report(message.Line, "message %q should be PascalCase", message.FullName)
+			}
+		})
+	},
+	RuleFieldLowerSnakeCase: func(file *schema.File, report reportFunc) {
+		walkMessages(file.Messages, func(message *schema.Message) {
+			for _, field := range message.Fields {
+				if !lowerSnakeCase.MatchString(field.Name) {
+					report(field.Line, "field %s.%s should be lower_snake_case", message.FullName, field.Name)
+				}
+			}
+		})
+	},
+	RuleFieldNoRequired: func(file *schema.File, report reportFunc) {
+		walkMessages(file.Messages, func(message *schema.Message) {
+			for _, field := range message.Fields {
+				if field.Required {
+					report(field.Line, "field %s.%s should not be required", message.FullName, field.Name)
+				}
+			}
+		})
+	},
+	RuleOneofLowerSnakeCase: func(file *schema.File, report reportFunc) {
+		walkMessages(file.Messages, func(message *schema.Message) {
+			seen := map[string]bool{}
+			for _, field := range message.Fields {
+				if field.Oneof == "" || seen[field.Oneof] {
+					continue
+				}
+				seen[field.Oneof] = true
+				if !lowerSnakeCase.MatchString(field.Oneof) {
+					report(field.Line, "oneof %s.%s should be lower_snake_case", message.FullName, field.Oneof)
+				}
+			}
+		})
+	},
+	RuleEnumPascalCase: func(file *schema.File, report reportFunc) {
+		walkEnums(file, func(enum *schema.Enum) {
+			if !pascalCase.MatchString(enum.Name) {
+				report(enum.Line, "enum %q should be PascalCase", enum.FullName)
+			}
+		})
+	},
+	RuleEnumValueUpperSnakeCase: func(file *schema.File, report reportFunc) {
+		walkEnums(file, func(enum *schema.Enum) {
+			for _, value := range enum.Values {
+				if !upperSnakeCase.MatchString(value.Name) {
+					report(value.Line, "enum value %s.%s should be UPPER_SNAKE_CASE", enum.FullName, value.Name)
+				}
+			}
+		})
+	},
+	RuleEnumZeroValueSuffix: func(file *schema.File, report reportFunc) {
+		walkEnums(file, func(enum *schema.Enum) {
+			for _, value := range enum.Values {
+				if value.Number == 0 && !strings.HasSuffix(value.Name, enumZeroValueSuffix) {
+					report(value.Line, "enum zero value %s.%s should end with %s", enum.FullName, value.Name, enumZeroValueSuffix)
+				}
+			}
+		})
+	},
+	RuleServicePascalCase: func(file *schema.File, report reportFunc) {
+		for _, service := range file.Services {
+			if !pascalCase.MatchString(service.Name) {
+				report(service.Line, "service %q should be PascalCase", service.FullName)
+			}
+		}
+	},
+	RuleServiceComment: func(file *schema.File, report reportFunc) {
+		for _, service := range file.Services {
+			if service.Comment == "" {
+				report(service.Line, "service %q should have a comment", service.FullName)
+			}
+		}
+	},
+	RuleRPCPascalCase: func(file *schema.File, report reportFunc) {
+		for _, service := range file.Services {
+			for _, method := range service.Methods {
+				if !pascalCase.MatchString(method.Name) {
+					report(method.Line, "rpc %s.%s should be PascalCase", service.FullName, method.Name)
+				}
+			}
+		}
+	},
+}
+
+func walkMessages(messages []*schema.Message, fn func(message *schema.Message)) {
+	for _, message := range messages {
+		fn(message)
+		walkMessages(message.Messages, fn)
+	}
+}
+
+func walkEnums(file *schema.File, fn func(enum *schema.Enum)) {
+	for _, enum := range file.Enums {
+		fn(enum)
+	}
+	walkMessages(file.Messages, func(message *schema.Message) {
+		for _, enum := range message.Enums {
+			fn(enum)
+		}
+	})
+}
+
+func packageLine(file *schema.File) int {
+	for _, pkg := range file.Proto.ProtoBody.Packages {
+		return pkg.Meta.Pos.Line
+	}
+	return 1
+}
@@ -0,0 +1,240 @@
+package metadata
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+)
+
+// Change kinds
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Changed elements
+const (
+	ElementMessage   = "message"
+	ElementField     = "field"
+	ElementEnum      = "enum"
+	ElementEnumValue = "enum_value"
+	ElementService   = "service"
+	ElementRPC       = "rpc"
+)
+
+// Change is a structural change of a schema element
+type Change struct {
+	Kind    string   `json:"kind"`
+	Element string   `json:"element"`
+	Name    string   `json:"name"`
+	Details []string `json:"details,omitempty"`
+}
+
+func (c *Change) String() string {
+	s := fmt.Sprintf("%s `%s`", strings.ReplaceAll(c.Element, "_", " "), c.Name)
+	if len(c.Details) > 0 {
+		s += ": " + strings.Join(c.Details, "; ")
+	}
+	return s
+}
+
+// element is a schema element flattened by its full name
+type element struct {
+	kind       string
+	attributes []attribute
+}
+
+// attribute is a compared property of an element
+type attribute struct {
+	name  string
+	value string
+}
+
+// Diff returns the changes of the messages, fields, enums, enum values,
+// services and RPCs between two metadata versions.
+// The changes are sorted by kind and name
+func Diff(from, to []*v1.Package) []*Change {
+	previous := flatten(from)
+	current := flatten(to)
+
+	var changes []*Change
+	for name, before := range previous {
+		after, ok := current[name]
+		if !ok {
+			changes = append(changes, &Change{Kind: ChangeRemoved, Element: before.kind, Name: name})
+			continue
+		}
+
+		// e.g. a field replaced by a nested message of the same name
+		if before.kind != after.kind || len(before.attributes) != len(after.attributes) {
+			changes = append(changes,
+				&Change{Kind: ChangeRemoved, Element: before.kind, Name: name},
+				&Change{Kind: ChangeAdded, Element: after.kind, Name: name, Details: summary(after)})
+			continue
+		}
+
+		var details []string
+		for i, attr := range before.attributes {
+			if attr.value != after.attributes[i].value {
+				details = append(details, fmt.Sprintf("%s %s -> %s",
+					attr.name, describe(attr.value), describe(after.attributes[i].value)))
+			}
+		}
+
+		if len(details) > 0 {
+			changes = append(changes, &Change{Kind: ChangeChanged, Element: before.kind, Name: name, Details: details})
+		}
+	}
+
+	for name, after := range current {
+		if _, ok := previous[name]; !ok {
+			changes = append(changes, &Change{Kind: ChangeAdded, Element: after.kind, Name: name, Details: summary(after)})
+		}
+	}
+
+	kindOrder := map[string]int{ChangeAdded: 0, ChangeRemoved: 1, ChangeChanged: 2}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return kindOrder[changes[i].Kind] < kindOrder[changes[j].Kind]
+		}
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
+
+// WriteChangelog writes the changes grouped by kind as Markdown
+func WriteChangelog(w io.Writer, title string, changes []*Change) error {
+	var out strings.Builder
+	fmt.Fprintf(&out, "## %s\n\n", title)
+
+	if len(changes) == 0 {
+		out.WriteString("No schema changes.\n")
+		_, err := io.WriteString(w, out.String())
+		return err
+	}
+
+	for _, kind := range []string{ChangeAdded, ChangeRemoved, ChangeChanged} {
+		header := false
+		for _, change := range changes {
+			if change.Kind != kind {
+				continue
+			}
+			if !header {
+				fmt.Fprintf(&out, "### %s\n\n", strings.ToUpper(kind[:1])+kind[1:])
+				header = true
+			}
+			fmt.Fprintf(&out, "- %s\n", change)
+		}
+		if header {
+			out.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(w, strings.TrimSuffix(out.String(), "\n"))
+	return err
+}
+
+func flatten(packages []*v1.Package) map[string]*element {
+	elements := map[string]*element{}
+
+	for _, pkg := range packages {
+		for _, file := range pkg.GetProtoFiles() {
+			for _, service := range file.GetServices() {
+				serviceName := join(pkg.GetName(), service.GetName())
+				elements[serviceName] = &element{kind: ElementService}
+
+				for _, method := range service.GetMethods() {
+					elements[join(serviceName, method.GetName())] = &element{
+						kind: ElementRPC,
+						attributes: []attribute{
+							{name: "request", value: method.GetInputType()},
+							{name: "response", value: method.GetOutputType()},
+						},
+					}
+				}
+			}
+
+			for _, message := range file.GetMessages() {
+				flattenMessage(elements, pkg.GetName(), message)
+			}
+		}
+	}
+
+	return elements
+}
+
+func flattenMessage(elements map[string]*element, scope string, message *v1.Message) {
+	fullName := join(scope, message.GetName())
+	elements[fullName] = &element{kind: ElementMessage}
+
+	for _, field := range message.GetFields() {
+		if field.GetOneof() {
+			// the oneof variants are compared as the fields of the oneof
+			for i, name := range field.GetOneofNames() {
+				typeName := ""
+				if i < len(field.GetOneofTypes()) {
+					typeName = field.GetOneofTypes()[i]
+				}
+				elements[join(fullName, name)] = &element{
+					kind:       ElementField,
+					attributes: fieldAttributes("", typeName, "", field.GetName()),
+				}
+			}
+			continue
+		}
+
+		number := fmt.Sprintf("%d", field.GetTag())
+		elements[join(fullName, field.GetName())] = &element{
+			kind:       ElementField,
+			attributes: fieldAttributes(number, fieldType(field), fieldLabel(field), ""),
+		}
+	}
+
+	for _, enum := range message.GetNestedEnums() {
+		enumName := join(fullName, enum.GetName())
+		elements[enumName] = &element{kind: ElementEnum}
+
+		for _, value := range enum.GetValues() {
+			elements[join(enumName, value.GetName())] = &element{
+				kind:       ElementEnumValue,
+				attributes: []attribute{{name: "number", value: fmt.Sprintf("%d", value.GetTag())}},
+			}
+		}
+	}
+
+	for _, nested := range message.GetNestedMessages() {
+		flattenMessage(elements, fullName, nested)
+	}
+}
+
+func fieldAttributes(number, typeName, label, oneof string) []attribute {
+	return []attribute{
+		{name: "number", value: number},
+		{name: "type", value: typeName},
+		{name: "label", value: label},
+		{name: "oneof", value: oneof},
+	}
+}
+
+// summary describes the added element
+func summary(e *element) []string {
+	var details []string
+	for _, attr := range e.attributes {
+		if attr.value != "" {
+			details = append(details, attr.name+" "+attr.value)
+		}
+	}
+	return details
+}
+
+func describe(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
@@ -0,0 +1,111 @@
+package metadata
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+)
+
+// typeRef is a message or an enum declared in one of the packages
+type typeRef struct {
+	Package  string
+	FullName string
+}
+
+// typeIndex resolves the field types to the declared messages and enums
+type typeIndex struct {
+	types map[string]typeRef
+}
+
+func newTypeIndex(packages []*v1.Package) *typeIndex {
+	index := &typeIndex{types: map[string]typeRef{}}
+
+	for _, pkg := range packages {
+		for _, file := range pkg.GetProtoFiles() {
+			for _, message := range file.GetMessages() {
+				index.addMessage(pkg.GetName(), pkg.GetName(), message)
+			}
+		}
+	}
+
+	return index
+}
+
+func (i *typeIndex) addMessage(pkg, scope string, message *v1.Message) {
+	fullName := join(scope, message.GetName())
+	i.types[fullName] = typeRef{Package: pkg, FullName: fullName}
+
+	for _, enum := range message.GetNestedEnums() {
+		enumName := join(fullName, enum.GetName())
+		i.types[enumName] = typeRef{Package: pkg, FullName: enumName}
+	}
+
+	for _, nested := range message.GetNestedMessages() {
+		i.addMessage(pkg, fullName, nested)
+	}
+}
+
+// resolve finds the type referenced in the scope following the protobuf scoping rules:
+// the innermost scope is searched first, then the enclosing ones
+func (i *typeIndex) resolve(scope, typeName string) (typeRef, bool) {
+	if strings.HasPrefix(typeName, ".") {
+		ref, ok := i.types[strings.TrimPrefix(typeName, ".")]
+		return ref, ok
+	}
+
+	for {
+		if ref, ok := i.types[join(scope, typeName)]; ok {
+			return ref, true
+		}
+
+		if scope == "" {
+			return typeRef{}, false
+		}
+
+		dot := strings.LastIndex(scope, ".")
+		if dot < 0 {
+			scope = ""
+		} else {
+			scope = scope[:dot]
+		}
+	}
+}
+
+func join(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// fieldType returns the declared type of the field, e.g. `map<string, Money>`
+func fieldType(field *v1.Field) string {
+	if field.GetMap() {
+		return "map<" + field.GetMapKeyType() + ", " + field.GetMapValueType() + ">"
+	}
+	return field.GetMessageType()
+}
+
+// Declaration returns the declaration of a non-oneof field, e.g. `repeated string tags = 5`
+func Declaration(field *v1.Field) string {
+	label := fieldLabel(field)
+	if label != "" {
+		label += " "
+	}
+	return fmt.Sprintf("%s%s %s = %d", label, fieldType(field), field.GetName(), field.GetTag())
+}
+
+// fieldLabel returns the label of the field, empty for singular fields
+func fieldLabel(field *v1.Field) string {
+	switch {
+	case field.GetRepeated():
+		return "repeated"
+	case field.GetOptional():
+		return "optional"
+	case field.GetRequired():
+		return "required"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,227 @@
+package metadata
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+)
+
+// IndexPage is the name of the page listing the packages
+const IndexPage = "index.md"
+
+// Markdown renders the API reference with one page per package.
+// Field and method types declared in the packages link to their definitions.
+// It returns the page contents by their file names
+func Markdown(title string, packages []*v1.Package) map[string]string {
+	index := newTypeIndex(packages)
+
+	known := map[string]bool{}
+	for _, pkg := range packages {
+		known[pkg.GetName()] = true
+	}
+
+	pages := map[string]string{}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "# %s\n\n", title)
+	names := make([]string, 0, len(packages))
+	for _, pkg := range packages {
+		names = append(names, pkg.GetName())
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&out, "- [%s](%s)\n", name, PageName(name))
+	}
+	pages[IndexPage] = out.String()
+
+	for _, pkg := range packages {
+		page := &markdownPage{pkg: pkg.GetName(), index: index}
+		pages[PageName(pkg.GetName())] = page.render(pkg, known)
+	}
+
+	return pages
+}
+
+// PageName returns the file name of the package page
+func PageName(pkg string) string {
+	if pkg == "" {
+		return "default.md"
+	}
+	return pkg + ".md"
+}
+
+type markdownPage struct {
+	pkg   string
+	index *typeIndex
+	out   strings.Builder
+}
+
+func (p *markdownPage) render(pkg *v1.Package, known map[string]bool) string {
+	p.printf("# Package `%s`\n\n", pkg.GetName())
+
+	p.printf("[Index](%s)\n\n", IndexPage)
+
+	p.printf("Files:\n\n")
+	for _, file := range pkg.GetProtoFiles() {
+		p.printf("- `%s`\n", file.GetFilename())
+	}
+	p.printf("\n")
+
+	if dependencies := pkg.GetDependencies(); len(dependencies) > 0 {
+		p.printf("Dependencies:\n\n")
+		for _, dependency := range dependencies {
+			if known[dependency.GetName()] {
+				p.printf("- [%s](%s)\n", dependency.GetName(), PageName(dependency.GetName()))
+			} else {
+				p.printf("- `%s`\n", dependency.GetName())
+			}
+		}
+		p.printf("\n")
+	}
+
+	var services []*v1.Service
+	var messages []*messageEntry
+	var enums []*enumEntry
+	for _, file := range pkg.GetProtoFiles() {
+		services = append(services, file.GetServices()...)
+		for _, message := range file.GetMessages() {
+			messages, enums = collectMessage(messages, enums, pkg.GetName(), message)
+		}
+	}
+
+	if len(services) > 0 {
+		p.printf("## Services\n\n")
+		for _, service := range services {
+			p.heading(join(p.pkg, service.GetName()), service.GetName())
+			p.printf("| Method | Request | Response |\n| --- | --- | --- |\n")
+			for _, method := range service.GetMethods() {
+				p.printf("| %s | %s | %s |\n", method.GetName(),
+					p.link(p.pkg, method.GetInputType()), p.link(p.pkg, method.GetOutputType()))
+			}
+			p.printf("\n")
+		}
+	}
+
+	if len(messages) > 0 {
+		p.printf("## Messages\n\n")
+		for _, entry := range messages {
+			p.renderMessage(entry)
+		}
+	}
+
+	if len(enums) > 0 {
+		p.printf("## Enums\n\n")
+		for _, entry := range enums {
+			p.heading(entry.fullName, p.relative(entry.fullName))
+			p.printf("| Name | Number |\n| --- | --- |\n")
+			for _, value := range entry.enum.GetValues() {
+				p.printf("| %s | %d |\n", value.GetName(), value.GetTag())
+			}
+			p.printf("\n")
+		}
+	}
+
+	return p.out.String()
+}
+
+func (p *markdownPage) renderMessage(entry *messageEntry) {
+	p.heading(entry.fullName, p.relative(entry.fullName))
+
+	fields := entry.message.GetFields()
+	if len(fields) == 0 {
+		p.printf("No fields.\n\n")
+		return
+	}
+
+	p.printf("| Field | Type | Number | Label |\n| --- | --- | --- | --- |\n")
+	for _, field := range fields {
+		if field.GetOneof() {
+			for i, name := range field.GetOneofNames() {
+				typeName := ""
+				if i < len(field.GetOneofTypes()) {
+					typeName = p.link(entry.fullName, field.GetOneofTypes()[i])
+				}
+				p.printf("| %s | %s |  | oneof `%s` |\n", name, typeName, field.GetName())
+			}
+			continue
+		}
+
+		typeName := p.link(entry.fullName, field.GetMessageType())
+		if field.GetMap() {
+			typeName = fmt.Sprintf("map&lt;%s, %s&gt;",
+				p.link(entry.fullName, field.GetMapKeyType()), p.link(entry.fullName, field.GetMapValueType()))
+		}
+
+		p.printf("| %s | %s | %d | %s |\n", field.GetName(), typeName, field.GetTag(), fieldLabel(field))
+	}
+	p.printf("\n")
+}
+
+// link returns the link to the type definition, or the type name if the type is not declared in the packages
+func (p *markdownPage) link(scope, typeName string) string {
+	ref, ok := p.index.resolve(scope, typeName)
+	if !ok {
+		return "`" + typeName + "`"
+	}
+
+	page := ""
+	if ref.Package != p.pkg {
+		page = PageName(ref.Package)
+	}
+
+	return fmt.Sprintf("[%s](%s#%s)", typeName, page, anchor(ref.FullName))
+}
+
+func (p *markdownPage) heading(fullName, title string) {
+	p.printf("<a id=\"%s\"></a>\n\n### %s\n\n", anchor(fullName), title)
+}
+
+// relative returns the name relative to the package, e.g. `PayRequest.Method`
+func (p *markdownPage) relative(fullName string) string {
+	if p.pkg == "" {
+		return fullName
+	}
+	return strings.TrimPrefix(fullName, p.pkg+".")
+}
+
+func (p *markdownPage) printf(format string, args ...any) {
+	fmt.Fprintf(&p.out, format, args...)
+}
+
+type messageEntry struct {
+	fullName string
+	message  *v1.Message
+}
+
+type enumEntry struct {
+	fullName string
+	enum     *v1.Enum
+}
+
+// collectMessage flattens the message with its nested messages and enums
+func collectMessage(
+	messages []*messageEntry,
+	enums []*enumEntry,
+	scope string,
+	message *v1.Message,
+) ([]*messageEntry, []*enumEntry) {
+	fullName := join(scope, message.GetName())
+	messages = append(messages, &messageEntry{fullName: fullName, message: message})
+
+	for _, enum := range message.GetNestedEnums() {
+		enums = append(enums, &enumEntry{fullName: join(fullName, enum.GetName()), enum: enum})
+	}
+
+	for _, nested := range message.GetNestedMessages() {
+		messages, enums = collectMessage(messages, enums, fullName, nested)
+	}
+
+	return messages, enums
+}
+
+// anchor returns the HTML id of the type, e.g. `acme-v1-payrequest` for `acme.v1.PayRequest`
+func anchor(fullName string) string {
+	return strings.ToLower(strings.ReplaceAll(fullName, ".", "-"))
+}
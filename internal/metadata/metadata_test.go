@@ -0,0 +1,269 @@
+package metadata
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+)
+
+var testPackages = []*v1.Package{
+	{
+		Name: "acme.v1",
+		ProtoFiles: []*v1.ParsedProtoFile{
+			{
+				Filename: "acme/v1/payments.proto",
+				Services: []*v1.Service{
+					{
+						Name:    "Payments",
+						Methods: []*v1.Method{{Name: "Pay", InputType: "PayRequest", OutputType: "acme.common.v1.Id"}},
+					},
+				},
+				Messages: []*v1.Message{
+					{
+						Name: "PayRequest",
+						Fields: []*v1.Field{
+							{Name: "id", MessageType: "string", Tag: 1},
+							{Name: "amounts", Map: true, MapKeyType: "string", MapValueType: "Money", Tag: 2},
+							{Name: "method", Oneof: true, OneofNames: []string{"card", "wallet"}, OneofTypes: []string{"Card", "string"}},
+							{Name: "tags", MessageType: "string", Tag: 5, Repeated: true},
+						},
+						NestedMessages: []*v1.Message{
+							{Name: "Card", Fields: []*v1.Field{{Name: "status", MessageType: "Status", Tag: 1}}},
+						},
+						NestedEnums: []*v1.Enum{
+							{Name: "Status", Values: []*v1.EnumValue{{Name: "STATUS_UNSPECIFIED"}, {Name: "STATUS_ACTIVE", Tag: 1}}},
+						},
+					},
+					{Name: "Money"},
+				},
+			},
+		},
+		Dependencies: []*v1.PackageDependency{{Name: "acme.common.v1"}},
+	},
+	{
+		Name: "acme.common.v1",
+		ProtoFiles: []*v1.ParsedProtoFile{
+			{
+				Filename: "acme/common/v1/id.proto",
+				Messages: []*v1.Message{{Name: "Id", Fields: []*v1.Field{{Name: "value", MessageType: "string", Tag: 1}}}},
+			},
+		},
+	},
+}
+
+func TestWriteTree(t *testing.T) {
+	want := `acme.v1
+├── acme/v1/payments.proto
+│   ├── service Payments
+│   │   └── rpc Pay(PayRequest) returns (acme.common.v1.Id)
+│   ├── message PayRequest
+│   │   ├── string id = 1
+│   │   ├── map<string, Money> amounts = 2
+│   │   ├── oneof method
+│   │   │   ├── Card card
+│   │   │   └── string wallet
+│   │   ├── repeated string tags = 5
+│   │   ├── message Card
+│   │   │   └── Status status = 1
+│   │   └── enum Status
+│   │       ├── STATUS_UNSPECIFIED = 0
+│   │       └── STATUS_ACTIVE = 1
+│   └── message Money
+└── dependencies
+    └── acme.common.v1
+
+acme.common.v1
+└── acme/common/v1/id.proto
+    └── message Id
+        └── string value = 1
+`
+
+	var out bytes.Buffer
+	if err := WriteTree(&out, testPackages); err != nil {
+		t.Fatalf("WriteTree() error = %v", err)
+	}
+
+	if got := out.String(); got != want {
+		t.Errorf("WriteTree() got = %v, want %v", got, want)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	pages := Markdown("acme/payments v1.0.0", testPackages)
+
+	tests := []struct {
+		name string
+		page string
+		want []string
+	}{
+		{
+			name: "index",
+			page: IndexPage,
+			want: []string{
+				"# acme/payments v1.0.0",
+				"- [acme.common.v1](acme.common.v1.md)\n- [acme.v1](acme.v1.md)",
+			},
+		},
+		{
+			name: "links in the same page",
+			page: "acme.v1.md",
+			want: []string{
+				"| Pay | [PayRequest](#acme-v1-payrequest) | [acme.common.v1.Id](acme.common.v1.md#acme-common-v1-id) |",
+				"| amounts | map&lt;`string`, [Money](#acme-v1-money)&gt; | 2 |  |",
+				"| card | [Card](#acme-v1-payrequest-card) |  | oneof `method` |",
+				"| tags | `string` | 5 | repeated |",
+				"<a id=\"acme-v1-payrequest-card\"></a>\n\n### PayRequest.Card",
+				"| status | [Status](#acme-v1-payrequest-status) | 1 |  |",
+				"| STATUS_ACTIVE | 1 |",
+				"- [acme.common.v1](acme.common.v1.md)",
+			},
+		},
+		{
+			name: "dependency page",
+			page: "acme.common.v1.md",
+			want: []string{
+				"# Package `acme.common.v1`",
+				"<a id=\"acme-common-v1-id\"></a>\n\n### Id",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, ok := pages[tt.page]
+			if !ok {
+				t.Fatalf("Markdown() page %s not found", tt.page)
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(page, want) {
+					t.Errorf("Markdown() page %s does not contain %q:\n%s", tt.page, want, page)
+				}
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	message := func(fields ...*v1.Field) []*v1.Package {
+		return []*v1.Package{
+			{
+				Name: "acme.v1",
+				ProtoFiles: []*v1.ParsedProtoFile{
+					{
+						Messages: []*v1.Message{{Name: "PayRequest", Fields: fields}},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name string
+		from []*v1.Package
+		to   []*v1.Package
+		want []string
+	}{
+		{
+			name: "no changes",
+			from: testPackages,
+			to:   testPackages,
+		},
+		{
+			name: "field added",
+			from: message(),
+			to:   message(&v1.Field{Name: "note", MessageType: "string", Tag: 6, Optional: true}),
+			want: []string{"added field `acme.v1.PayRequest.note`: number 6; type string; label optional"},
+		},
+		{
+			name: "field number, type and label changed",
+			from: message(&v1.Field{Name: "id", MessageType: "string", Tag: 1}),
+			to:   message(&v1.Field{Name: "id", MessageType: "int64", Tag: 2, Repeated: true}),
+			want: []string{"changed field `acme.v1.PayRequest.id`: number 1 -> 2; type string -> int64; label none -> repeated"},
+		},
+		{
+			name: "map value type changed",
+			from: message(&v1.Field{Name: "amounts", Map: true, MapKeyType: "string", MapValueType: "int64", Tag: 2}),
+			to:   message(&v1.Field{Name: "amounts", Map: true, MapKeyType: "string", MapValueType: "Money", Tag: 2}),
+			want: []string{"changed field `acme.v1.PayRequest.amounts`: type map<string, int64> -> map<string, Money>"},
+		},
+		{
+			name: "field moved into oneof",
+			from: message(&v1.Field{Name: "card", MessageType: "Card", Tag: 3}),
+			to:   message(&v1.Field{Name: "method", Oneof: true, OneofNames: []string{"card"}, OneofTypes: []string{"Card"}}),
+			want: []string{"changed field `acme.v1.PayRequest.card`: number 3 -> none; oneof none -> method"},
+		},
+		{
+			name: "field replaced by a nested message",
+			from: message(&v1.Field{Name: "Item", MessageType: "string", Tag: 1}),
+			to: []*v1.Package{
+				{
+					Name: "acme.v1",
+					ProtoFiles: []*v1.ParsedProtoFile{
+						{
+							Messages: []*v1.Message{{Name: "PayRequest", NestedMessages: []*v1.Message{{Name: "Item"}}}},
+						},
+					},
+				},
+			},
+			want: []string{
+				"added message `acme.v1.PayRequest.Item`",
+				"removed field `acme.v1.PayRequest.Item`",
+			},
+		},
+		{
+			name: "elements removed",
+			from: testPackages,
+			to:   testPackages[1:],
+			want: []string{
+				"removed message `acme.v1.Money`",
+				"removed message `acme.v1.PayRequest`",
+				"removed message `acme.v1.PayRequest.Card`",
+				"removed field `acme.v1.PayRequest.Card.status`",
+				"removed enum `acme.v1.PayRequest.Status`",
+				"removed enum value `acme.v1.PayRequest.Status.STATUS_ACTIVE`",
+				"removed enum value `acme.v1.PayRequest.Status.STATUS_UNSPECIFIED`",
+				"removed field `acme.v1.PayRequest.amounts`",
+				"removed field `acme.v1.PayRequest.card`",
+				"removed field `acme.v1.PayRequest.id`",
+				"removed field `acme.v1.PayRequest.tags`",
+				"removed field `acme.v1.PayRequest.wallet`",
+				"removed service `acme.v1.Payments`",
+				"removed rpc `acme.v1.Payments.Pay`",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, change := range Diff(tt.from, tt.to) {
+				got = append(got, change.Kind+" "+change.String())
+			}
+
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Diff() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteChangelog(t *testing.T) {
+	changes := []*Change{
+		{Kind: ChangeAdded, Element: ElementEnumValue, Name: "acme.v1.Status.STATUS_DONE", Details: []string{"number 2"}},
+		{Kind: ChangeChanged, Element: ElementRPC, Name: "acme.v1.Payments.Pay", Details: []string{"response Id -> PayResponse"}},
+	}
+
+	want := "## acme/payments v1.0.0...v1.1.0\n\n" +
+		"### Added\n\n- enum value `acme.v1.Status.STATUS_DONE`: number 2\n\n" +
+		"### Changed\n\n- rpc `acme.v1.Payments.Pay`: response Id -> PayResponse\n"
+
+	var out bytes.Buffer
+	if err := WriteChangelog(&out, "acme/payments v1.0.0...v1.1.0", changes); err != nil {
+		t.Fatalf("WriteChangelog() error = %v", err)
+	}
+
+	if got := out.String(); got != want {
+		t.Errorf("WriteChangelog() got = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,113 @@
+package metadata
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+)
+
+type node struct {
+	label    string
+	children []*node
+}
+
+func (n *node) add(label string) *node {
+	child := &node{label: label}
+	n.children = append(n.children, child)
+	return child
+}
+
+// WriteTree writes the packages as an indented tree:
+// package, files, services with methods, messages with fields, nested messages and enums
+func WriteTree(w io.Writer, packages []*v1.Package) error {
+	for i, pkg := range packages {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+
+		root := &node{label: pkg.GetName()}
+		for _, file := range pkg.GetProtoFiles() {
+			fileNode := root.add(file.GetFilename())
+			for _, service := range file.GetServices() {
+				serviceNode := fileNode.add("service " + service.GetName())
+				for _, method := range service.GetMethods() {
+					serviceNode.add(fmt.Sprintf("rpc %s(%s) returns (%s)",
+						method.GetName(), method.GetInputType(), method.GetOutputType()))
+				}
+			}
+			for _, message := range file.GetMessages() {
+				addMessageNode(fileNode, message)
+			}
+		}
+
+		if dependencies := pkg.GetDependencies(); len(dependencies) > 0 {
+			dependenciesNode := root.add("dependencies")
+			for _, dependency := range dependencies {
+				dependenciesNode.add(dependency.GetName())
+			}
+		}
+
+		if _, err := fmt.Fprintln(w, root.label); err != nil {
+			return err
+		}
+		if err := writeChildren(w, root, ""); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func addMessageNode(parent *node, message *v1.Message) {
+	messageNode := parent.add("message " + message.GetName())
+
+	for _, field := range message.GetFields() {
+		if field.GetOneof() {
+			oneofNode := messageNode.add("oneof " + field.GetName())
+			for i, name := range field.GetOneofNames() {
+				typeName := ""
+				if i < len(field.GetOneofTypes()) {
+					typeName = field.GetOneofTypes()[i] + " "
+				}
+				oneofNode.add(typeName + name)
+			}
+			continue
+		}
+
+		messageNode.add(Declaration(field))
+	}
+
+	for _, nested := range message.GetNestedMessages() {
+		addMessageNode(messageNode, nested)
+	}
+
+	for _, enum := range message.GetNestedEnums() {
+		enumNode := messageNode.add("enum " + enum.GetName())
+		for _, value := range enum.GetValues() {
+			enumNode.add(fmt.Sprintf("%s = %d", value.GetName(), value.GetTag()))
+		}
+	}
+}
+
+func writeChildren(w io.Writer, parent *node, prefix string) error {
+	for i, child := range parent.children {
+		branch, indent := "├── ", "│   "
+		if i == len(parent.children)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		if _, err := fmt.Fprintln(w, strings.TrimRight(prefix+branch+child.label, " ")); err != nil {
+			return err
+		}
+
+		if err := writeChildren(w, child, prefix+indent); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,6 +2,8 @@ package model
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -16,10 +18,18 @@ type Config struct {
 	Registry Registry  `yaml:"registry,omitempty"`
 	Export   Export    `yaml:"export,omitempty"`
 	Modules  []*Module `yaml:"modules,omitempty"`
+	Generate []*Plugin `yaml:"generate,omitempty"`
+	Lint     Lint      `yaml:"lint,omitempty"`
 }
 
+// Export configures the files pushed to the registry.
+// Exclude globs are matched against the paths relative to the project root.
+// Root is stripped from the pushed filenames, so consumers import
+// `proto/acme/v1/x.proto` as `acme/v1/x.proto` with root `proto`
 type Export struct {
-	Paths []string `yaml:"paths,omitempty"`
+	Paths   []string `yaml:"paths,omitempty"`
+	Exclude []string `yaml:"exclude,omitempty"`
+	Root    string   `yaml:"root,omitempty"`
 }
 
 type Registry struct {
@@ -37,10 +47,40 @@ type Module struct {
 	GenerateOutputFolder string `yaml:"gen_out,omitempty"`
 }
 
+// Lint configures `pbuf lint`. All rules are used when Rules is empty
+// Ignore maps a rule to the paths it is not applied to
+type Lint struct {
+	Rules  []string            `yaml:"rules,omitempty"`
+	Except []string            `yaml:"except,omitempty"`
+	Ignore map[string][]string `yaml:"ignore,omitempty"`
+}
+
+// Plugin is a protoc plugin invoked by `pbuf generate`
+// Name is resolved as protoc-gen-<name> from PATH unless Path is set
+type Plugin struct {
+	Name string `yaml:"plugin,omitempty"`
+	Path string `yaml:"path,omitempty"`
+	Out  string `yaml:"out,omitempty"`
+	Opt  string `yaml:"opt,omitempty"`
+}
+
 func (c *Config) HasRegistry() bool {
 	return c.Registry.Addr != ""
 }
 
+// AddModule adds the registry module with the tag,
+// or updates the tag if the module is already in the config
+func (c *Config) AddModule(name, tag string) {
+	for _, module := range c.Modules {
+		if module.Name == name && module.Repository == "" {
+			module.Tag = tag
+			return
+		}
+	}
+
+	c.Modules = append(c.Modules, &Module{Name: name, Tag: tag})
+}
+
 func (c *Config) Save() error {
 	// encode to yaml and save to file PbufConfigFilename
 	pbufYamlFile, err := os.OpenFile(PbufConfigFilename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
@@ -57,3 +97,40 @@ func (c *Config) Save() error {
 
 	return nil
 }
+
+// VendorDir returns the local folder the module files are vendored to
+func (m *Module) VendorDir() string {
+	if m.OutputFolder != "" {
+		return m.OutputFolder
+	}
+
+	if strings.HasSuffix(m.Path, ".proto") {
+		return filepath.Dir(m.Path)
+	}
+
+	if m.Path != "" {
+		return m.Path
+	}
+
+	return "."
+}
+
+// ImportPath returns the path the vendored file is imported with,
+// i.e. the file path with the vendor folder replaced by the module path
+func (m *Module) ImportPath(filename string) string {
+	root := m.Path
+	if strings.HasSuffix(root, ".proto") {
+		root = filepath.Dir(root)
+	}
+
+	relative := filename
+	if dir := m.VendorDir(); dir != "." {
+		relative = strings.TrimPrefix(filename, dir+"/")
+	}
+
+	if root == "" || root == "." {
+		return relative
+	}
+
+	return filepath.ToSlash(filepath.Join(root, relative))
+}
@@ -1,6 +1,7 @@
 package modules
 
 import (
+	"fmt"
 	"log"
 	"os"
 
@@ -46,6 +47,7 @@ func newProtoPatchers() []patcher.Patcher {
 func Vendor(config *model.Config, netrcAuth *netrc.Netrc, client v1.RegistryClient) error {
 	patchers := newProtoPatchers()
 
+	var lockedModules []*registry.LockedModule
 	for _, module := range config.Modules {
 		if module.Repository == "" {
 			if config.HasRegistry() {
@@ -57,10 +59,12 @@ func Vendor(config *model.Config, netrcAuth *netrc.Netrc, client v1.RegistryClie
 					log.Fatalf("no module tag found for module: %v", module)
 				}
 
-				err := registry.VendorRegistryModule(module, client, patchers)
+				locked, err := registry.VendorRegistryModule(module, client, patchers)
 				if err != nil {
 					log.Fatalf("failed to vendor module %s: %v", module.Name, err)
 				}
+
+				lockedModules = append(lockedModules, locked)
 			} else {
 				log.Fatalf("no repository found for module: %s", module.Name)
 			}
@@ -72,5 +76,40 @@ func Vendor(config *model.Config, netrcAuth *netrc.Netrc, client v1.RegistryClie
 		}
 	}
 
+	if len(lockedModules) > 0 {
+		err := updateLock(config, lockedModules)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateLock records the vendored registry modules in the lock file
+// and removes the modules no longer in the config
+func updateLock(config *model.Config, lockedModules []*registry.LockedModule) error {
+	lock, err := registry.LoadLock(registry.LockFilename)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, module := range config.Modules {
+		if module.Repository == "" {
+			names = append(names, module.Name)
+		}
+	}
+	lock.Retain(names)
+
+	for _, locked := range lockedModules {
+		lock.Set(locked)
+	}
+
+	err = lock.Save(registry.LockFilename)
+	if err != nil {
+		return fmt.Errorf("failed to save %s: %w", registry.LockFilename, err)
+	}
+
 	return nil
 }
@@ -0,0 +1,219 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is an output format
+type Format string
+
+const (
+	FormatJSON     Format = "json"
+	FormatYAML     Format = "yaml"
+	FormatTable    Format = "table"
+	FormatWide     Format = "wide"
+	FormatTemplate Format = "template"
+	FormatSARIF    Format = "sarif"
+	FormatJUnit    Format = "junit"
+)
+
+// Formats lists the supported formats. The sarif and junit formats
+// are supported only by the commands reporting findings
+var Formats = []Format{FormatJSON, FormatYAML, FormatTable, FormatWide, FormatTemplate, FormatSARIF, FormatJUnit}
+
+// Column is a table column. Wide columns are shown only in the wide format
+type Column struct {
+	Name string
+	Wide bool
+}
+
+// Table is the table view of the data. Rows contain the cells of all columns
+type Table struct {
+	Columns  []Column
+	Rows     [][]string
+	NoHeader bool
+}
+
+// Renderer writes the data in the output format
+type Renderer struct {
+	out      io.Writer
+	format   Format
+	template *template.Template
+}
+
+// NewRenderer creates a renderer. The template is required for the template format
+func NewRenderer(out io.Writer, format string, text string) (*Renderer, error) {
+	renderer := &Renderer{out: out, format: Format(format)}
+
+	switch renderer.format {
+	case FormatJSON, FormatYAML, FormatTable, FormatWide, FormatSARIF, FormatJUnit:
+	case FormatTemplate:
+		if text == "" {
+			return nil, fmt.Errorf("--template is required for the template output")
+		}
+
+		tmpl, err := template.New("output").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template: %w", err)
+		}
+		renderer.template = tmpl
+	default:
+		return nil, fmt.Errorf("unknown output format %q. use json, yaml, table, wide, template, sarif or junit", format)
+	}
+
+	return renderer, nil
+}
+
+// Format returns the output format
+func (r *Renderer) Format() Format {
+	return r.format
+}
+
+// IsTable reports whether the output is a table or a wide table
+func (r *Renderer) IsTable() bool {
+	return r.format == FormatTable || r.format == FormatWide
+}
+
+// Render writes the data. The table is used for the table and wide formats.
+// Without the table, the data is written as JSON
+func (r *Renderer) Render(data any, table *Table) error {
+	if r.IsTable() && table != nil {
+		return r.RenderTable(table)
+	}
+
+	if r.IsReport() {
+		return fmt.Errorf("output format %s is not supported by this command", r.format)
+	}
+
+	value, err := Value(data)
+	if err != nil {
+		return err
+	}
+
+	switch r.format {
+	case FormatYAML:
+		encoder := yaml.NewEncoder(r.out)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(value); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case FormatTemplate:
+		return r.template.Execute(r.out, value)
+	default:
+		marshalled, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(r.out, string(marshalled))
+		return err
+	}
+}
+
+// RenderTable writes the table. Wide columns are skipped unless the format is wide
+func (r *Renderer) RenderTable(table *Table) error {
+	var visible []int
+	var header []string
+	for i, column := range table.Columns {
+		if column.Wide && r.format != FormatWide {
+			continue
+		}
+		visible = append(visible, i)
+		header = append(header, column.Name)
+	}
+
+	w := tabwriter.NewWriter(r.out, 0, 0, 2, ' ', 0)
+	if !table.NoHeader {
+		if _, err := fmt.Fprintln(w, strings.Join(header, "\t")); err != nil {
+			return err
+		}
+	}
+
+	for _, row := range table.Rows {
+		cells := make([]string, 0, len(visible))
+		for _, i := range visible {
+			cell := ""
+			if i < len(row) {
+				cell = row[i]
+			}
+			cells = append(cells, cell)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(cells, "\t")); err != nil {
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+var protoMarshaller = protojson.MarshalOptions{UseProtoNames: true}
+
+// Value converts the data to plain maps, slices and scalars.
+// Protobuf messages are converted with protojson and keep the proto field names
+func Value(data any) (any, error) {
+	if message, ok := data.(proto.Message); ok {
+		if reflect.ValueOf(message).IsNil() {
+			return nil, nil
+		}
+		marshalled, err := protoMarshaller.Marshal(message)
+		if err != nil {
+			return nil, err
+		}
+		return unmarshal(marshalled)
+	}
+
+	value := reflect.ValueOf(data)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		result := make([]any, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			item, err := Value(value.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, item)
+		}
+		return result, nil
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			break
+		}
+		result := make(map[string]any, value.Len())
+		for _, key := range value.MapKeys() {
+			item, err := Value(value.MapIndex(key).Interface())
+			if err != nil {
+				return nil, err
+			}
+			result[key.String()] = item
+		}
+		return result, nil
+	default:
+	}
+
+	marshalled, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshal(marshalled)
+}
+
+func unmarshal(marshalled []byte) (any, error) {
+	var value any
+	if err := json.Unmarshal(marshalled, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
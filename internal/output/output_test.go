@@ -0,0 +1,215 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+)
+
+func TestRender(t *testing.T) {
+	modules := []*v1.Module{
+		{Id: "1", Name: "acme/payments", Tags: []string{"v1.0.0"}, DraftTags: []string{"feature-x"}},
+		{Id: "2", Name: "acme/orders"},
+	}
+
+	table := &Table{
+		Columns: []Column{{Name: "NAME"}, {Name: "TAGS"}, {Name: "ID", Wide: true}},
+		Rows: [][]string{
+			{"acme/payments", "1", "1"},
+			{"acme/orders", "0", "2"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		format   string
+		template string
+		data     any
+		table    *Table
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:   "json",
+			format: "json",
+			data:   modules,
+			table:  table,
+			want: `[
+  {
+    "draft_tags": [
+      "feature-x"
+    ],
+    "id": "1",
+    "name": "acme/payments",
+    "tags": [
+      "v1.0.0"
+    ]
+  },
+  {
+    "id": "2",
+    "name": "acme/orders"
+  }
+]
+`,
+		},
+		{
+			name:   "yaml",
+			format: "yaml",
+			data:   map[string]any{"module": modules[1], "count": 1},
+			want: `count: 1
+module:
+  id: "2"
+  name: acme/orders
+`,
+		},
+		{
+			name:     "template",
+			format:   "template",
+			template: "{{range .}}{{.name}} {{len .tags}}\n{{end}}",
+			data:     modules[:1],
+			want:     "acme/payments 1\n",
+		},
+		{
+			name:   "table",
+			format: "table",
+			data:   modules,
+			table:  table,
+			want: `NAME           TAGS
+acme/payments  1
+acme/orders    0
+`,
+		},
+		{
+			name:   "wide",
+			format: "wide",
+			data:   modules,
+			table:  table,
+			want: `NAME           TAGS  ID
+acme/payments  1     1
+acme/orders    0     2
+`,
+		},
+		{
+			name:   "table without table view",
+			format: "table",
+			data:   modules[1],
+			want: `{
+  "id": "2",
+  "name": "acme/orders"
+}
+`,
+		},
+		{
+			name:    "template without template",
+			format:  "template",
+			wantErr: true,
+		},
+		{
+			name:    "unknown format",
+			format:  "xml",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			renderer, err := NewRenderer(&out, tt.format, tt.template)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewRenderer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if err := renderer.Render(tt.data, tt.table); err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+
+			if got := out.String(); got != tt.want {
+				t.Errorf("Render() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderReport(t *testing.T) {
+	report := &Report{
+		Name:  "drift",
+		Rules: []Rule{{ID: "drift/modified", Description: "A file was modified"}},
+		Findings: []*Finding{
+			{
+				RuleID:     "drift/modified",
+				Level:      LevelError,
+				Message:    "a.proto was modified",
+				Group:      "acme/payments@v1.0.0",
+				Name:       "a.proto",
+				Location:   "a.proto",
+				Properties: map[string]string{"id": "1"},
+			},
+			{RuleID: "drift/added", Level: LevelNote, Message: "b.proto was added", Group: "acme/payments@v1.0.0", Name: "b.proto"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		want   []string
+	}{
+		{
+			name:   "sarif",
+			format: "sarif",
+			want: []string{
+				`"version": "2.1.0"`,
+				`"id": "drift/modified"`,
+				`"ruleId": "drift/modified",
+          "level": "error",
+          "message": {
+            "text": "a.proto was modified"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "a.proto"`,
+				`"properties": {
+            "id": "1"`,
+			},
+		},
+		{
+			name:   "junit",
+			format: "junit",
+			want: []string{
+				`<?xml version="1.0" encoding="UTF-8"?>`,
+				`<testsuites name="drift" tests="2" failures="1">`,
+				`<testcase name="a.proto" classname="acme/payments@v1.0.0">
+      <failure message="a.proto was modified" type="drift/modified">a.proto was modified&#xA;id: 1</failure>`,
+				`<testcase name="b.proto" classname="acme/payments@v1.0.0">
+      <system-out>b.proto was added</system-out>`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			renderer, err := NewRenderer(&out, tt.format, "")
+			if err != nil {
+				t.Fatalf("NewRenderer() error = %v", err)
+			}
+
+			if err := renderer.RenderReport(report); err != nil {
+				t.Fatalf("RenderReport() error = %v", err)
+			}
+
+			for _, want := range tt.want {
+				if !bytes.Contains(out.Bytes(), []byte(want)) {
+					t.Errorf("RenderReport() does not contain %s:\n%s", want, out.String())
+				}
+			}
+
+			if err := renderer.Render(report, nil); err == nil {
+				t.Errorf("Render() expected error for the %s format", tt.format)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package output
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Level is the level of a finding, named after the SARIF levels
+type Level string
+
+const (
+	LevelError   Level = "error"
+	LevelWarning Level = "warning"
+	LevelNote    Level = "note"
+)
+
+// Report is the list of findings written in the sarif and junit formats
+type Report struct {
+	// Name is the JUnit test suite name
+	Name     string
+	Rules    []Rule
+	Findings []*Finding
+}
+
+// Rule describes the findings with the same rule id
+type Rule struct {
+	ID          string
+	Description string
+}
+
+// Finding is a SARIF result and a JUnit test case. Errors and warnings are JUnit failures
+type Finding struct {
+	RuleID  string
+	Level   Level
+	Message string
+	// Group is the JUnit class name, e.g. the module
+	Group string
+	// Name is the JUnit test case name, e.g. the file
+	Name string
+	// Location is the SARIF artifact URI, optional
+	Location   string
+	Properties map[string]string
+}
+
+// Failed reports whether the finding is a JUnit failure
+func (f *Finding) Failed() bool {
+	return f.Level == LevelError || f.Level == LevelWarning
+}
+
+// IsReport reports whether the format is a report format
+func (r *Renderer) IsReport() bool {
+	return r.format == FormatSARIF || r.format == FormatJUnit
+}
+
+// RenderReport writes the report in the sarif or junit format
+func (r *Renderer) RenderReport(report *Report) error {
+	switch r.format {
+	case FormatSARIF:
+		return writeSARIF(r.out, report)
+	case FormatJUnit:
+		return writeJUnit(r.out, report)
+	default:
+		return fmt.Errorf("output format %s is not a report format", r.format)
+	}
+}
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	toolName     = "pbuf"
+	toolURI      = "https://github.com/pbufio/pbuf-cli"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules,omitempty"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	Level      Level             `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations,omitempty"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+func writeSARIF(w io.Writer, report *Report) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{Name: toolName, InformationURI: toolURI},
+		},
+		Results: []sarifResult{},
+	}
+
+	for _, rule := range report.Rules {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:               rule.ID,
+			ShortDescription: sarifMessage{Text: rule.Description},
+		})
+	}
+
+	for _, finding := range report.Findings {
+		result := sarifResult{
+			RuleID:     finding.RuleID,
+			Level:      finding.Level,
+			Message:    sarifMessage{Text: finding.Message},
+			Properties: finding.Properties,
+		}
+		if finding.Location != "" {
+			result.Locations = []sarifLocation{
+				{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: finding.Location}}},
+			}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	marshalled, err := json.MarshalIndent(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(marshalled))
+	return err
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func writeJUnit(w io.Writer, report *Report) error {
+	suite := junitTestSuite{Name: report.Name}
+
+	for _, finding := range report.Findings {
+		testCase := junitTestCase{Name: finding.Name, ClassName: finding.Group}
+
+		details := finding.Message
+		keys := make([]string, 0, len(finding.Properties))
+		for key := range finding.Properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			details += fmt.Sprintf("\n%s: %s", key, finding.Properties[key])
+		}
+
+		if finding.Failed() {
+			testCase.Failure = &junitFailure{Message: finding.Message, Type: finding.RuleID, Text: details}
+			suite.Failures++
+		} else {
+			testCase.SystemOut = details
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+		suite.Tests++
+	}
+
+	suites := junitTestSuites{
+		Name:     report.Name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	marshalled, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, marshalled)
+	return err
+}
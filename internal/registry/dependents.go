@@ -0,0 +1,93 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+)
+
+// ListModulesPageSize is the page size used to list all modules
+const ListModulesPageSize = 100
+
+// Dependent is a module tag that depends on another module
+type Dependent struct {
+	Name          string
+	Tag           string
+	DependencyTag string
+}
+
+func (d *Dependent) String() string {
+	return fmt.Sprintf("%s@%s (pins %s)", d.Name, d.Tag, d.DependencyTag)
+}
+
+// ListAllModules lists the modules of all pages
+func ListAllModules(ctx context.Context, client v1.RegistryClient) ([]*v1.Module, error) {
+	var modules []*v1.Module
+
+	pageToken := ""
+	for {
+		response, err := client.ListModules(ctx, &v1.ListModulesRequest{
+			PageSize:  ListModulesPageSize,
+			PageToken: pageToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list modules: %w", err)
+		}
+
+		modules = append(modules, response.Modules...)
+
+		pageToken = response.NextPageToken
+		if pageToken == "" {
+			return modules, nil
+		}
+	}
+}
+
+// FindDependents scans the tags of all registry modules
+// and returns the ones that depend on the module tag.
+// If the tag is empty, the dependents of any tag are returned
+func FindDependents(ctx context.Context, client v1.RegistryClient, name, tag string) ([]*Dependent, error) {
+	modules, err := ListAllModules(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	var dependents []*Dependent
+	for _, module := range modules {
+		if module.Name == name {
+			continue
+		}
+
+		withTags, err := client.GetModule(ctx, &v1.GetModuleRequest{
+			Name:             module.Name,
+			IncludeDraftTags: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get module %s: %w", module.Name, err)
+		}
+
+		moduleTags := append(append([]string{}, withTags.Tags...), withTags.DraftTags...)
+		for _, moduleTag := range moduleTags {
+			response, err := client.GetModuleDependencies(ctx, &v1.GetModuleDependenciesRequest{
+				Name: module.Name,
+				Tag:  moduleTag,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get dependencies of %s@%s: %w", module.Name, moduleTag, err)
+			}
+
+			for _, dependency := range response.Dependencies {
+				if dependency.Name == name && (tag == "" || dependency.Tag == tag) {
+					dependents = append(dependents, &Dependent{
+						Name:          module.Name,
+						Tag:           moduleTag,
+						DependencyTag: dependency.Tag,
+					})
+				}
+			}
+		}
+	}
+
+	return dependents, nil
+}
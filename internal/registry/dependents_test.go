@@ -0,0 +1,104 @@
+package registry
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"google.golang.org/grpc"
+)
+
+// fakeRegistryClient serves the modules split into pages of one module
+type fakeRegistryClient struct {
+	v1.RegistryClient
+	modules      []*v1.Module
+	dependencies map[string][]*v1.Dependency
+}
+
+func (c *fakeRegistryClient) ListModules(_ context.Context, in *v1.ListModulesRequest, _ ...grpc.CallOption) (*v1.ListModulesResponse, error) {
+	page := 0
+	if in.PageToken != "" {
+		page = int(in.PageToken[0] - '0')
+	}
+
+	response := &v1.ListModulesResponse{Modules: c.modules[page : page+1]}
+	if page+1 < len(c.modules) {
+		response.NextPageToken = string(rune('0' + page + 1))
+	}
+
+	return response, nil
+}
+
+func (c *fakeRegistryClient) GetModule(_ context.Context, in *v1.GetModuleRequest, _ ...grpc.CallOption) (*v1.Module, error) {
+	for _, module := range c.modules {
+		if module.Name == in.Name {
+			return module, nil
+		}
+	}
+	return nil, context.Canceled
+}
+
+func (c *fakeRegistryClient) GetModuleDependencies(_ context.Context, in *v1.GetModuleDependenciesRequest, _ ...grpc.CallOption) (*v1.GetModuleDependenciesResponse, error) {
+	return &v1.GetModuleDependenciesResponse{Dependencies: c.dependencies[in.Name+"@"+in.Tag]}, nil
+}
+
+func TestFindDependents(t *testing.T) {
+	client := &fakeRegistryClient{
+		modules: []*v1.Module{
+			{Name: "acme/common", Tags: []string{"v1.0.0", "v1.1.0"}},
+			{Name: "acme/payments", Tags: []string{"v1.0.0", "v2.0.0"}, DraftTags: []string{"feature-x"}},
+			{Name: "acme/orders", Tags: []string{"v1.0.0"}},
+		},
+		dependencies: map[string][]*v1.Dependency{
+			"acme/payments@v1.0.0":    {{Name: "acme/common", Tag: "v1.0.0"}},
+			"acme/payments@v2.0.0":    {{Name: "acme/common", Tag: "v1.1.0"}},
+			"acme/payments@feature-x": {{Name: "acme/common", Tag: "v1.1.0"}},
+			"acme/orders@v1.0.0":      {{Name: "acme/payments", Tag: "v2.0.0"}},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		moduleName string
+		tag        string
+		want       []string
+	}{
+		{
+			name:       "tag",
+			moduleName: "acme/common",
+			tag:        "v1.1.0",
+			want:       []string{"acme/payments@v2.0.0 (pins v1.1.0)", "acme/payments@feature-x (pins v1.1.0)"},
+		},
+		{
+			name:       "any tag",
+			moduleName: "acme/common",
+			want: []string{
+				"acme/payments@v1.0.0 (pins v1.0.0)",
+				"acme/payments@v2.0.0 (pins v1.1.0)",
+				"acme/payments@feature-x (pins v1.1.0)",
+			},
+		},
+		{
+			name:       "no dependents",
+			moduleName: "acme/orders",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dependents, err := FindDependents(context.Background(), client, tt.moduleName, tt.tag)
+			if err != nil {
+				t.Fatalf("FindDependents() error = %v", err)
+			}
+
+			var got []string
+			for _, dependent := range dependents {
+				got = append(got, dependent.String())
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindDependents() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package registry
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/model"
+)
+
+// CollectExportedProtoFiles collects the proto files of the export paths
+// with the names consumers see: the excluded files are skipped
+// and the export root is stripped from the filenames
+func CollectExportedProtoFiles(export model.Export) ([]*v1.ProtoFile, error) {
+	protoFiles, err := CollectProtoFilesInDirs(export.Paths)
+	if err != nil {
+		return nil, err
+	}
+
+	protoFiles = ExcludeProtoFiles(protoFiles, export.Exclude)
+
+	if export.Root == "" {
+		return protoFiles, nil
+	}
+
+	root := path.Clean(export.Root) + "/"
+	for _, protoFile := range protoFiles {
+		filename := path.Clean(protoFile.Filename)
+		if !strings.HasPrefix(filename, root) {
+			return nil, fmt.Errorf("file %s is outside of the export root %s", protoFile.Filename, export.Root)
+		}
+		protoFile.Filename = strings.TrimPrefix(filename, root)
+	}
+
+	return protoFiles, nil
+}
+
+// ExcludeProtoFiles returns the files that do not match any of the globs
+func ExcludeProtoFiles(protoFiles []*v1.ProtoFile, exclude []string) []*v1.ProtoFile {
+	if len(exclude) == 0 {
+		return protoFiles
+	}
+
+	var result []*v1.ProtoFile
+	for _, protoFile := range protoFiles {
+		excluded := false
+		for _, pattern := range exclude {
+			if MatchGlob(pattern, protoFile.Filename) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			result = append(result, protoFile)
+		}
+	}
+
+	return result
+}
+
+// MatchGlob reports whether the slash separated name matches the glob.
+// Besides the path.Match syntax, `**` matches any number of directories.
+// A glob matching a directory matches all files under it
+func MatchGlob(pattern, name string) bool {
+	patternParts := strings.Split(path.Clean(pattern), "/")
+	nameParts := strings.Split(path.Clean(name), "/")
+
+	for i := 1; i <= len(nameParts); i++ {
+		if matchParts(patternParts, nameParts[:i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchParts(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchParts(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(name) == 0 {
+		return false
+	}
+
+	matched, err := path.Match(pattern[0], name[0])
+	if err != nil || !matched {
+		return false
+	}
+
+	return matchParts(pattern[1:], name[1:])
+}
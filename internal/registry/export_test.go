@@ -0,0 +1,109 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pbufio/pbuf-cli/internal/model"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "api/v1/test.proto", name: "api/v1/test.proto", want: true},
+		{pattern: "api/*/test.proto", name: "api/v1/test.proto", want: true},
+		{pattern: "**/*_test.proto", name: "api/v1/payments_test.proto", want: true},
+		{pattern: "**/*_test.proto", name: "payments_test.proto", want: true},
+		{pattern: "**/*_test.proto", name: "api/v1/payments.proto", want: false},
+		{pattern: "api/internal", name: "api/internal/v1/x.proto", want: true},
+		{pattern: "**/internal", name: "proto/acme/internal/x.proto", want: true},
+		{pattern: "api/int", name: "api/internal/x.proto", want: false},
+		{pattern: "api/**/x.proto", name: "api/x.proto", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.name, func(t *testing.T) {
+			if got := MatchGlob(tt.pattern, tt.name); got != tt.want {
+				t.Errorf("MatchGlob() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectExportedProtoFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	for _, name := range []string{
+		"proto/acme/v1/x.proto",
+		"proto/acme/v1/x_test.proto",
+		"proto/acme/internal/y.proto",
+		"other/z.proto",
+	} {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(`syntax = "proto3";`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		export  model.Export
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:   "all files",
+			export: model.Export{Paths: []string{"proto"}},
+			want:   []string{"proto/acme/internal/y.proto", "proto/acme/v1/x.proto", "proto/acme/v1/x_test.proto"},
+		},
+		{
+			name: "excluded files",
+			export: model.Export{
+				Paths:   []string{"proto"},
+				Exclude: []string{"**/*_test.proto", "proto/acme/internal"},
+			},
+			want: []string{"proto/acme/v1/x.proto"},
+		},
+		{
+			name: "stripped root",
+			export: model.Export{
+				Paths:   []string{"proto"},
+				Exclude: []string{"**/*_test.proto"},
+				Root:    "proto/",
+			},
+			want: []string{"acme/internal/y.proto", "acme/v1/x.proto"},
+		},
+		{
+			name: "file outside of root",
+			export: model.Export{
+				Paths: []string{"proto", "other"},
+				Root:  "proto",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			protoFiles, err := CollectExportedProtoFiles(tt.export)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CollectExportedProtoFiles() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			var got []string
+			for _, protoFile := range protoFiles {
+				got = append(got, protoFile.Filename)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CollectExportedProtoFiles() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
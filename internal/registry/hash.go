@@ -0,0 +1,39 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+)
+
+// ContentHash returns the sha256 hash of the proto files and the dependencies.
+// The order of the files and the dependencies does not matter
+func ContentHash(protoFiles []*v1.ProtoFile, dependencies []*v1.Dependency) string {
+	files := make([]*v1.ProtoFile, len(protoFiles))
+	copy(files, protoFiles)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Filename < files[j].Filename
+	})
+
+	deps := make([]*v1.Dependency, len(dependencies))
+	copy(deps, dependencies)
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].Name != deps[j].Name {
+			return deps[i].Name < deps[j].Name
+		}
+		return deps[i].Tag < deps[j].Tag
+	})
+
+	hash := sha256.New()
+	for _, file := range files {
+		_, _ = fmt.Fprintf(hash, "file\x00%s\x00%d\x00%s", file.Filename, len(file.Content), file.Content)
+	}
+	for _, dep := range deps {
+		_, _ = fmt.Fprintf(hash, "dependency\x00%s\x00%s\x00", dep.Name, dep.Tag)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,56 @@
+package registry
+
+import (
+	"testing"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+)
+
+func TestContentHash(t *testing.T) {
+	files := []*v1.ProtoFile{
+		{Filename: "api/v1/a.proto", Content: "a"},
+		{Filename: "api/v1/b.proto", Content: "b"},
+	}
+	dependencies := []*v1.Dependency{
+		{Name: "acme/common", Tag: "v1.0.0"},
+		{Name: "acme/money", Tag: "v2.0.0"},
+	}
+	hash := ContentHash(files, dependencies)
+
+	tests := []struct {
+		name         string
+		files        []*v1.ProtoFile
+		dependencies []*v1.Dependency
+		wantEqual    bool
+	}{
+		{
+			name:         "different order",
+			files:        []*v1.ProtoFile{files[1], files[0]},
+			dependencies: []*v1.Dependency{dependencies[1], dependencies[0]},
+			wantEqual:    true,
+		},
+		{
+			name:         "changed content",
+			files:        []*v1.ProtoFile{files[0], {Filename: "api/v1/b.proto", Content: "c"}},
+			dependencies: dependencies,
+		},
+		{
+			name:         "content moved between files",
+			files:        []*v1.ProtoFile{{Filename: "api/v1/a.proto", Content: "ab"}, {Filename: "api/v1/b.proto"}},
+			dependencies: dependencies,
+		},
+		{
+			name:         "changed dependency tag",
+			files:        files,
+			dependencies: []*v1.Dependency{dependencies[0], {Name: "acme/money", Tag: "v2.1.0"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ContentHash(tt.files, tt.dependencies) == hash
+			if got != tt.wantEqual {
+				t.Errorf("ContentHash() equal = %v, want %v", got, tt.wantEqual)
+			}
+		})
+	}
+}
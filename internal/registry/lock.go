@@ -0,0 +1,130 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LockFilename is the file recording the vendored registry module files
+const LockFilename = "pbuf.lock"
+
+// Lock records the files vendored from the registry modules
+type Lock struct {
+	Modules []*LockedModule `yaml:"modules,omitempty"`
+}
+
+// LockedModule is a vendored registry module tag
+type LockedModule struct {
+	Name  string        `yaml:"name"`
+	Tag   string        `yaml:"tag"`
+	Files []*LockedFile `yaml:"files,omitempty"`
+}
+
+// LockedFile is a vendored file. Hash is the hash of the registry content.
+// LocalHash is set when the vendored content differs from the registry one, e.g. patched
+type LockedFile struct {
+	Filename  string `yaml:"filename"`
+	Path      string `yaml:"path"`
+	Hash      string `yaml:"hash"`
+	LocalHash string `yaml:"local_hash,omitempty"`
+}
+
+// VendoredHash returns the hash of the content written to the path
+func (f *LockedFile) VendoredHash() string {
+	if f.LocalHash != "" {
+		return f.LocalHash
+	}
+	return f.Hash
+}
+
+// FileHash returns the hex encoded sha256 of the file content
+func FileHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadLock reads the lock file. It returns an empty lock if the file does not exist
+func LoadLock(path string) (*Lock, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Lock{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	lock := &Lock{}
+	if err := yaml.Unmarshal(content, lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return lock, nil
+}
+
+// Save writes the lock file with the modules and files sorted by name
+func (l *Lock) Save(path string) error {
+	sort.Slice(l.Modules, func(i, j int) bool {
+		return l.Modules[i].Name < l.Modules[j].Name
+	})
+	for _, module := range l.Modules {
+		sort.Slice(module.Files, func(i, j int) bool {
+			return module.Files[i].Filename < module.Files[j].Filename
+		})
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := yaml.NewEncoder(file)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(l); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// Module returns the locked module by name, nil if it is not locked
+func (l *Lock) Module(name string) *LockedModule {
+	for _, module := range l.Modules {
+		if module.Name == name {
+			return module
+		}
+	}
+	return nil
+}
+
+// Set adds or replaces the locked module
+func (l *Lock) Set(module *LockedModule) {
+	for i, locked := range l.Modules {
+		if locked.Name == module.Name {
+			l.Modules[i] = module
+			return
+		}
+	}
+	l.Modules = append(l.Modules, module)
+}
+
+// Retain removes the modules with names not in the list
+func (l *Lock) Retain(names []string) {
+	keep := map[string]bool{}
+	for _, name := range names {
+		keep[name] = true
+	}
+
+	var modules []*LockedModule
+	for _, module := range l.Modules {
+		if keep[module.Name] {
+			modules = append(modules, module)
+		}
+	}
+	l.Modules = modules
+}
@@ -0,0 +1,63 @@
+package registry
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockFilename)
+
+	lock, err := LoadLock(path)
+	if err != nil {
+		t.Fatalf("LoadLock() error = %v", err)
+	}
+	if len(lock.Modules) != 0 {
+		t.Errorf("LoadLock() got = %v, want empty lock", lock.Modules)
+	}
+
+	lock.Set(&LockedModule{Name: "acme/payments", Tag: "v1.0.0"})
+	lock.Set(&LockedModule{Name: "acme/common", Tag: "v1.0.0"})
+	lock.Set(&LockedModule{Name: "acme/removed", Tag: "v1.0.0"})
+	lock.Set(&LockedModule{
+		Name: "acme/payments",
+		Tag:  "v1.1.0",
+		Files: []*LockedFile{
+			{Filename: "b.proto", Path: "api/b.proto", Hash: FileHash([]byte("b"))},
+			{Filename: "a.proto", Path: "api/a.proto", Hash: FileHash([]byte("a")), LocalHash: FileHash([]byte("patched"))},
+		},
+	})
+	lock.Retain([]string{"acme/payments", "acme/common"})
+
+	if err := lock.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadLock(path)
+	if err != nil {
+		t.Fatalf("LoadLock() error = %v", err)
+	}
+
+	want := &Lock{
+		Modules: []*LockedModule{
+			{Name: "acme/common", Tag: "v1.0.0"},
+			{
+				Name: "acme/payments",
+				Tag:  "v1.1.0",
+				Files: []*LockedFile{
+					{Filename: "a.proto", Path: "api/a.proto", Hash: FileHash([]byte("a")), LocalHash: FileHash([]byte("patched"))},
+					{Filename: "b.proto", Path: "api/b.proto", Hash: FileHash([]byte("b"))},
+				},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(loaded, want) {
+		t.Errorf("LoadLock() got = %v, want %v", loaded, want)
+	}
+
+	if got := loaded.Module("acme/payments").Files[0].VendoredHash(); got != FileHash([]byte("patched")) {
+		t.Errorf("VendoredHash() got = %v, want the local hash", got)
+	}
+}
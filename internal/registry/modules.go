@@ -0,0 +1,52 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+)
+
+// WalkModules lists the modules page by page starting from the page token
+// and calls fn for each page until it returns false or there are no more pages.
+// It returns the token of the next page, empty if all pages are listed
+func WalkModules(
+	ctx context.Context,
+	client v1.RegistryClient,
+	pageSize int32,
+	pageToken string,
+	fn func(modules []*v1.Module) (bool, error),
+) (string, error) {
+	for {
+		response, err := client.ListModules(ctx, &v1.ListModulesRequest{
+			PageSize:  pageSize,
+			PageToken: pageToken,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to list modules: %w", err)
+		}
+
+		next, err := fn(response.Modules)
+		if err != nil {
+			return "", err
+		}
+
+		pageToken = response.NextPageToken
+		if !next || pageToken == "" {
+			return pageToken, nil
+		}
+	}
+}
+
+// MatchModuleName reports whether the module name matches the filter.
+// The filter is a glob if it contains `*`, `?` or `[`, and a name prefix otherwise
+func MatchModuleName(filter, name string) bool {
+	if !strings.ContainsAny(filter, "*?[") {
+		return strings.HasPrefix(name, filter)
+	}
+
+	matched, err := path.Match(filter, name)
+	return err == nil && matched
+}
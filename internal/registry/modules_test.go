@@ -0,0 +1,26 @@
+package registry
+
+import "testing"
+
+func TestMatchModuleName(t *testing.T) {
+	tests := []struct {
+		filter string
+		name   string
+		want   bool
+	}{
+		{filter: "acme/", name: "acme/payments", want: true},
+		{filter: "acme/pay", name: "acme/payments", want: true},
+		{filter: "acme/", name: "other/acme", want: false},
+		{filter: "acme/*-api", name: "acme/payments-api", want: true},
+		{filter: "acme/*-api", name: "acme/payments", want: false},
+		{filter: "*/payments", name: "acme/payments", want: true},
+		{filter: "*", name: "acme/payments", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter+" "+tt.name, func(t *testing.T) {
+			if got := MatchModuleName(tt.filter, tt.name); got != tt.want {
+				t.Errorf("MatchModuleName() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
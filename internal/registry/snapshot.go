@@ -0,0 +1,55 @@
+package registry
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SnapshotDir returns the directory of the vendored registry file contents
+// in the user cache directory, e.g. `~/.cache/pbuf/snapshots`
+func SnapshotDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user cache directory: %w", err)
+	}
+
+	return filepath.Join(dir, "pbuf", "snapshots"), nil
+}
+
+// SaveSnapshot stores the content in the directory under its hash
+func SaveSnapshot(dir string, content []byte) error {
+	path := filepath.Join(dir, FileHash(content))
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// LoadSnapshot returns the content stored under the hash. It returns false if there is no snapshot
+func LoadSnapshot(dir, hash string) ([]byte, bool, error) {
+	// the hash comes from the registry, make sure it is not a path
+	if _, err := hex.DecodeString(hash); err != nil || hash == "" {
+		return nil, false, nil
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, hash))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read snapshot %s: %w", hash, err)
+	}
+
+	return content, true, nil
+}
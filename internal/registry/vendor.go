@@ -16,8 +16,9 @@ import (
 
 const timeout = 60 * time.Second
 
-// VendorRegistryModule function that iterate over the modules and vendor proto files from PBUF registry
-func VendorRegistryModule(module *model.Module, client v1.RegistryClient, patchers []patcher.Patcher) error {
+// VendorRegistryModule function that iterate over the modules and vendor proto files from PBUF registry.
+// It returns the vendored files to record in the lock file
+func VendorRegistryModule(module *model.Module, client v1.RegistryClient, patchers []patcher.Patcher) (*LockedModule, error) {
 	log.Printf("start vendoring .proto files. module name: %s, path: %s", module.Name, module.Path)
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
@@ -30,51 +31,39 @@ func VendorRegistryModule(module *model.Module, client v1.RegistryClient, patche
 
 	if err != nil {
 		log.Printf("failed to pull module: %v", err)
-		return err
+		return nil, err
+	}
+
+	locked := &LockedModule{Name: module.Name, Tag: module.Tag}
+
+	// the registry contents are kept to show the changes of later drift events
+	snapshotDir, err := SnapshotDir()
+	if err != nil {
+		log.Printf("failed to store snapshots: %v", err)
 	}
 
 	var wg = &sync.WaitGroup{}
+	var mu = &sync.Mutex{}
 
 	for _, protoFile := range response.Protofiles {
-		originalFilename := protoFile.Filename
+		registryFilename := protoFile.Filename
 		protoFileContent := protoFile.Content
 		outputPath := module.OutputFolder
 
+		originalFilename, ok := VendorPath(module, registryFilename)
+		if !ok {
+			continue
+		}
+
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 
-			if module.Path != "" {
-				modulePath := module.Path
-
-				if strings.HasSuffix(module.Path, ".proto") {
-					// skip if the file is not in the module path
-					if originalFilename != module.Path {
-						return
-					}
-
-					// get directory
-					modulePath = filepath.Dir(module.Path)
-				} else {
-					// skip if the file is not in the module path
-					if !strings.HasPrefix(originalFilename, modulePath) {
-						return
-					}
-				}
-
-				if outputPath != "" {
-					originalFilename = strings.Replace(originalFilename, modulePath, outputPath, 1)
-				}
-			} else {
-				if outputPath != "" {
-					originalFilename = filepath.Join(outputPath, originalFilename)
-				}
-			}
-
 			var content string
 			outputDir := filepath.Dir(originalFilename)
 
 			if module.GenerateOutputFolder != "" {
+				var err error
 				content, err = patcher.ApplyPatchers(
 					patchers,
 					strings.Replace(outputDir, module.OutputFolder, module.GenerateOutputFolder, 1),
@@ -87,7 +76,7 @@ func VendorRegistryModule(module *model.Module, client v1.RegistryClient, patche
 				content = protoFileContent
 			}
 
-			err = os.MkdirAll(outputDir, os.ModePerm)
+			err := os.MkdirAll(outputDir, os.ModePerm)
 			if err != nil {
 				log.Fatalf("failed to create directory: %s", outputPath)
 			}
@@ -96,11 +85,31 @@ func VendorRegistryModule(module *model.Module, client v1.RegistryClient, patche
 			if err != nil {
 				log.Fatalf("failed to create file: %s", outputPath)
 			}
+			defer copiedFile.Close()
 
 			_, err = copiedFile.Write([]byte(content))
 			if err != nil {
 				log.Fatalf("failed to write file contents: %s", outputPath)
 			}
+
+			lockedFile := &LockedFile{
+				Filename: registryFilename,
+				Path:     filepath.ToSlash(originalFilename),
+				Hash:     FileHash([]byte(protoFileContent)),
+			}
+			if content != protoFileContent {
+				lockedFile.LocalHash = FileHash([]byte(content))
+			}
+
+			if snapshotDir != "" {
+				if err := SaveSnapshot(snapshotDir, []byte(protoFileContent)); err != nil {
+					log.Printf("failed to store snapshot of %s: %v", registryFilename, err)
+				}
+			}
+
+			mu.Lock()
+			locked.Files = append(locked.Files, lockedFile)
+			mu.Unlock()
 		}()
 	}
 
@@ -108,5 +117,40 @@ func VendorRegistryModule(module *model.Module, client v1.RegistryClient, patche
 
 	log.Printf("successfully vendoring .proto files. module name: %s, path: %s", module.Name, module.Path)
 
-	return nil
+	return locked, nil
+}
+
+// VendorPath returns the local path the registry file of the module is vendored to.
+// It returns false if the file is not in the module path
+func VendorPath(module *model.Module, filename string) (string, bool) {
+	outputPath := module.OutputFolder
+
+	if module.Path == "" {
+		if outputPath != "" {
+			return filepath.Join(outputPath, filename), true
+		}
+		return filename, true
+	}
+
+	modulePath := module.Path
+	if strings.HasSuffix(module.Path, ".proto") {
+		// skip if the file is not in the module path
+		if filename != module.Path {
+			return "", false
+		}
+
+		// get directory
+		modulePath = filepath.Dir(module.Path)
+	} else {
+		// skip if the file is not in the module path
+		if !strings.HasPrefix(filename, modulePath) {
+			return "", false
+		}
+	}
+
+	if outputPath != "" {
+		filename = strings.Replace(filename, modulePath, outputPath, 1)
+	}
+
+	return filename, true
 }
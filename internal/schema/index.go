@@ -0,0 +1,130 @@
+package schema
+
+import (
+	"strings"
+)
+
+// Kind is a kind of the named type
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	KindMessage
+	KindEnum
+)
+
+var scalarTypes = map[string]bool{
+	"double": true, "float": true,
+	"int32": true, "int64": true, "uint32": true, "uint64": true,
+	"sint32": true, "sint64": true, "fixed32": true, "fixed64": true,
+	"sfixed32": true, "sfixed64": true,
+	"bool": true, "string": true, "bytes": true,
+}
+
+// IsScalar checks whether the type is a scalar type
+func IsScalar(typeName string) bool {
+	return scalarTypes[typeName]
+}
+
+// Index is a lookup of the messages, enums and services by their full names
+type Index struct {
+	Files    []*File
+	Messages map[string]*Message
+	Enums    map[string]*Enum
+	Services map[string]*Service
+}
+
+// NewIndex creates an index for the files
+func NewIndex(files []*File) *Index {
+	index := &Index{
+		Files:    files,
+		Messages: map[string]*Message{},
+		Enums:    map[string]*Enum{},
+		Services: map[string]*Service{},
+	}
+
+	for _, file := range files {
+		for _, message := range file.Messages {
+			index.addMessage(message)
+		}
+		for _, enum := range file.Enums {
+			index.Enums[enum.FullName] = enum
+		}
+		for _, service := range file.Services {
+			index.Services[service.FullName] = service
+		}
+	}
+
+	return index
+}
+
+func (i *Index) addMessage(message *Message) {
+	i.Messages[message.FullName] = message
+	for _, nested := range message.Messages {
+		i.addMessage(nested)
+	}
+	for _, enum := range message.Enums {
+		i.Enums[enum.FullName] = enum
+	}
+}
+
+// Kind returns the kind of the named type
+func (i *Index) Kind(fullName string) Kind {
+	if _, ok := i.Messages[fullName]; ok {
+		return KindMessage
+	}
+	if _, ok := i.Enums[fullName]; ok {
+		return KindEnum
+	}
+	return KindUnknown
+}
+
+// Resolve finds the full name of the type referenced from the scope
+// using the protobuf scoping rules. Unknown types are returned as written
+func (i *Index) Resolve(scope, typeName string) string {
+	if IsScalar(typeName) {
+		return typeName
+	}
+
+	if strings.HasPrefix(typeName, ".") {
+		return strings.TrimPrefix(typeName, ".")
+	}
+
+	for {
+		candidate := join(scope, typeName)
+		if i.Kind(candidate) != KindUnknown {
+			return candidate
+		}
+
+		if scope == "" {
+			return typeName
+		}
+
+		dot := strings.LastIndex(scope, ".")
+		if dot < 0 {
+			scope = ""
+		} else {
+			scope = scope[:dot]
+		}
+	}
+}
+
+func (i *Index) resolveTypes() {
+	for _, message := range i.Messages {
+		for _, field := range message.Fields {
+			field.Type = i.Resolve(message.FullName, field.Type)
+		}
+	}
+
+	for _, service := range i.Services {
+		scope := strings.TrimSuffix(service.FullName, "."+service.Name)
+		if scope == service.FullName {
+			scope = ""
+		}
+
+		for _, method := range service.Methods {
+			method.InputType = i.Resolve(scope, method.InputType)
+			method.OutputType = i.Resolve(scope, method.OutputType)
+		}
+	}
+}
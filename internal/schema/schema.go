@@ -0,0 +1,329 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/yoheimuta/go-protoparser/v4"
+	"github.com/yoheimuta/go-protoparser/v4/interpret/unordered"
+	"github.com/yoheimuta/go-protoparser/v4/parser"
+)
+
+// File is a parsed .proto file with the types indexed by their full names
+type File struct {
+	Name     string
+	Syntax   string
+	Package  string
+	Imports  []string
+	Options  map[string]string
+	Messages []*Message
+	Enums    []*Enum
+	Services []*Service
+
+	Proto *unordered.Proto
+}
+
+type Message struct {
+	Name     string
+	FullName string
+	Fields   []*Field
+	Messages []*Message
+	Enums    []*Enum
+	Reserved Reserved
+	Line     int
+}
+
+type Field struct {
+	Name     string
+	Number   int
+	Type     string
+	KeyType  string
+	Repeated bool
+	Optional bool
+	Required bool
+	Map      bool
+	Oneof    string
+	Line     int
+}
+
+type Enum struct {
+	Name     string
+	FullName string
+	Values   []*EnumValue
+	Reserved Reserved
+	Line     int
+}
+
+type EnumValue struct {
+	Name   string
+	Number int
+	Line   int
+}
+
+type Service struct {
+	Name     string
+	FullName string
+	Methods  []*Method
+	Comment  string
+	Line     int
+}
+
+type Method struct {
+	Name            string
+	InputType       string
+	OutputType      string
+	ClientStreaming bool
+	ServerStreaming bool
+	Line            int
+}
+
+// Reserved holds reserved numbers and names of a message or an enum
+type Reserved struct {
+	Ranges [][2]int
+	Names  []string
+}
+
+// HasNumber checks whether the number is reserved
+func (r Reserved) HasNumber(number int) bool {
+	for _, reservedRange := range r.Ranges {
+		if number >= reservedRange[0] && number <= reservedRange[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// HasName checks whether the name is reserved
+func (r Reserved) HasName(name string) bool {
+	for _, reservedName := range r.Names {
+		if reservedName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseAll parses proto files and resolves field and method types to full names
+func ParseAll(protoFiles []*v1.ProtoFile) ([]*File, error) {
+	var files []*File
+	for _, protoFile := range protoFiles {
+		file, err := Parse(protoFile)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	NewIndex(files).resolveTypes()
+
+	return files, nil
+}
+
+// Parse parses a single proto file. Types stay as written in the file
+func Parse(protoFile *v1.ProtoFile) (*File, error) {
+	parsed, err := protoparser.Parse(
+		strings.NewReader(protoFile.Content),
+		protoparser.WithFilename(protoFile.Filename),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", protoFile.Filename, err)
+	}
+
+	proto, err := unordered.InterpretProto(parsed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to interpret %s: %w", protoFile.Filename, err)
+	}
+
+	file := &File{
+		Name:    protoFile.Filename,
+		Options: map[string]string{},
+		Proto:   proto,
+	}
+
+	if proto.Syntax != nil {
+		file.Syntax = strings.Trim(proto.Syntax.ProtobufVersion, `"'`)
+	}
+
+	for _, pkg := range proto.ProtoBody.Packages {
+		file.Package = pkg.Name
+	}
+
+	for _, imported := range proto.ProtoBody.Imports {
+		file.Imports = append(file.Imports, strings.Trim(imported.Location, `"'`))
+	}
+
+	for _, option := range proto.ProtoBody.Options {
+		file.Options[option.OptionName] = strings.Trim(option.Constant, `"'`)
+	}
+
+	for _, message := range proto.ProtoBody.Messages {
+		file.Messages = append(file.Messages, newMessage(file.Package, message))
+	}
+
+	for _, enum := range proto.ProtoBody.Enums {
+		file.Enums = append(file.Enums, newEnum(file.Package, enum))
+	}
+
+	for _, service := range proto.ProtoBody.Services {
+		file.Services = append(file.Services, newService(file.Package, service))
+	}
+
+	return file, nil
+}
+
+func newMessage(scope string, src *unordered.Message) *Message {
+	message := &Message{
+		Name:     src.MessageName,
+		FullName: join(scope, src.MessageName),
+		Line:     src.Meta.Pos.Line,
+	}
+
+	body := src.MessageBody
+
+	for _, field := range body.Fields {
+		message.Fields = append(message.Fields, &Field{
+			Name:     field.FieldName,
+			Number:   atoi(field.FieldNumber),
+			Type:     field.Type,
+			Repeated: field.IsRepeated,
+			Optional: field.IsOptional,
+			Required: field.IsRequired,
+			Line:     field.Meta.Pos.Line,
+		})
+	}
+
+	for _, mapField := range body.Maps {
+		message.Fields = append(message.Fields, &Field{
+			Name:    mapField.MapName,
+			Number:  atoi(mapField.FieldNumber),
+			Type:    mapField.Type,
+			KeyType: mapField.KeyType,
+			Map:     true,
+			Line:    mapField.Meta.Pos.Line,
+		})
+	}
+
+	for _, oneof := range body.Oneofs {
+		for _, field := range oneof.OneofFields {
+			message.Fields = append(message.Fields, &Field{
+				Name:   field.FieldName,
+				Number: atoi(field.FieldNumber),
+				Type:   field.Type,
+				Oneof:  oneof.OneofName,
+				Line:   field.Meta.Pos.Line,
+			})
+		}
+	}
+
+	sort.SliceStable(message.Fields, func(i, j int) bool {
+		return message.Fields[i].Line < message.Fields[j].Line
+	})
+
+	for _, nested := range body.Messages {
+		message.Messages = append(message.Messages, newMessage(message.FullName, nested))
+	}
+
+	for _, nested := range body.Enums {
+		message.Enums = append(message.Enums, newEnum(message.FullName, nested))
+	}
+
+	for _, reserved := range body.Reserves {
+		message.Reserved.add(reserved)
+	}
+
+	return message
+}
+
+func newEnum(scope string, src *unordered.Enum) *Enum {
+	enum := &Enum{
+		Name:     src.EnumName,
+		FullName: join(scope, src.EnumName),
+		Line:     src.Meta.Pos.Line,
+	}
+
+	for _, value := range src.EnumBody.EnumFields {
+		enum.Values = append(enum.Values, &EnumValue{
+			Name:   value.Ident,
+			Number: atoi(value.Number),
+			Line:   value.Meta.Pos.Line,
+		})
+	}
+
+	for _, reserved := range src.EnumBody.Reserveds {
+		enum.Reserved.add(reserved)
+	}
+
+	return enum
+}
+
+func newService(scope string, src *unordered.Service) *Service {
+	service := &Service{
+		Name:     src.ServiceName,
+		FullName: join(scope, src.ServiceName),
+		Comment:  comment(src.Comments),
+		Line:     src.Meta.Pos.Line,
+	}
+
+	for _, rpc := range src.ServiceBody.RPCs {
+		service.Methods = append(service.Methods, &Method{
+			Name:            rpc.RPCName,
+			InputType:       rpc.RPCRequest.MessageType,
+			OutputType:      rpc.RPCResponse.MessageType,
+			ClientStreaming: rpc.RPCRequest.IsStream,
+			ServerStreaming: rpc.RPCResponse.IsStream,
+			Line:            rpc.Meta.Pos.Line,
+		})
+	}
+
+	return service
+}
+
+func (r *Reserved) add(reserved *parser.Reserved) {
+	for _, reservedRange := range reserved.Ranges {
+		begin := atoi(reservedRange.Begin)
+		end := begin
+		switch reservedRange.End {
+		case "":
+		case "max":
+			end = maxFieldNumber
+		default:
+			end = atoi(reservedRange.End)
+		}
+		r.Ranges = append(r.Ranges, [2]int{begin, end})
+	}
+
+	for _, name := range reserved.FieldNames {
+		r.Names = append(r.Names, strings.Trim(name, `"'`))
+	}
+}
+
+// comment returns the text of the leading comments
+func comment(comments []*parser.Comment) string {
+	var lines []string
+	for _, c := range comments {
+		for _, line := range c.Lines() {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+const maxFieldNumber = 536870911
+
+func join(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+func atoi(s string) int {
+	value, err := strconv.ParseInt(s, 0, 64)
+	if err != nil {
+		return 0
+	}
+	return int(value)
+}
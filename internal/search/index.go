@@ -0,0 +1,140 @@
+package search
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/registry"
+)
+
+// Index is the symbols of the latest tags of the registry modules
+type Index struct {
+	UpdatedAt time.Time        `json:"updated_at"`
+	Modules   []*IndexedModule `json:"modules"`
+}
+
+// IndexedModule is the symbols of a module tag
+type IndexedModule struct {
+	Name    string    `json:"name"`
+	Tag     string    `json:"tag"`
+	Symbols []*Symbol `json:"symbols"`
+}
+
+// Fresh reports whether the index was updated within the max age
+func (i *Index) Fresh(maxAge time.Duration) bool {
+	return i != nil && time.Since(i.UpdatedAt) < maxAge
+}
+
+// Build indexes the latest tags of all registry modules.
+// The modules of the previous index with the same latest tag are reused
+// without fetching their metadata again
+func Build(
+	ctx context.Context,
+	registryClient v1.RegistryClient,
+	metadataClient v1.MetadataServiceClient,
+	previous *Index,
+) (*Index, error) {
+	indexed := map[string]*IndexedModule{}
+	if previous != nil {
+		for _, module := range previous.Modules {
+			indexed[module.Name+"@"+module.Tag] = module
+		}
+	}
+
+	modules, err := registry.ListAllModules(ctx, registryClient)
+	if err != nil {
+		return nil, err
+	}
+
+	index := &Index{UpdatedAt: time.Now()}
+	for _, module := range modules {
+		withTags, err := registryClient.GetModule(ctx, &v1.GetModuleRequest{Name: module.Name})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get module %s: %w", module.Name, err)
+		}
+
+		if len(withTags.Tags) == 0 {
+			continue
+		}
+		tag := withTags.Tags[0]
+
+		if cached, ok := indexed[module.Name+"@"+tag]; ok {
+			index.Modules = append(index.Modules, cached)
+			continue
+		}
+
+		response, err := metadataClient.GetMetadata(ctx, &v1.GetMetadataRequest{
+			Name: module.Name,
+			Tag:  tag,
+		})
+		if err != nil {
+			// the module is skipped and indexed again on the next build
+			log.Printf("failed to get metadata of %s@%s: %v", module.Name, tag, err)
+			continue
+		}
+
+		index.Modules = append(index.Modules, &IndexedModule{
+			Name:    module.Name,
+			Tag:     tag,
+			Symbols: Symbols(module.Name, tag, response.Packages),
+		})
+	}
+
+	return index, nil
+}
+
+// CachePath returns the path of the index cache of the registry
+// in the user cache directory, e.g. `~/.cache/pbuf/search-<hash>.json`
+func CachePath(registryAddr string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find user cache directory: %w", err)
+	}
+
+	sum := sha256.Sum256([]byte(registryAddr))
+	return filepath.Join(dir, "pbuf", "search-"+hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// Load reads the index cache. It returns nil if the cache does not exist
+func Load(path string) (*Index, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var index Index
+	if err := json.Unmarshal(content, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	return &index, nil
+}
+
+// Save writes the index cache
+func (i *Index) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+
+	content, err := json.Marshal(i)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/metadata"
+)
+
+// Symbol is a message, field, enum, service or RPC declared in a module tag
+type Symbol struct {
+	Module  string `json:"module"`
+	Tag     string `json:"tag"`
+	Package string `json:"package"`
+	File    string `json:"file"`
+	Kind    string `json:"kind"`
+	Path    string `json:"path"`
+}
+
+// Name returns the last element of the symbol path, e.g. `Money` for `acme.v1.Money`
+func (s *Symbol) Name() string {
+	return s.Path[strings.LastIndex(s.Path, ".")+1:]
+}
+
+// Kinds are the kinds of the indexed symbols
+var Kinds = []string{
+	metadata.ElementMessage,
+	metadata.ElementField,
+	metadata.ElementEnum,
+	metadata.ElementService,
+	metadata.ElementRPC,
+}
+
+// Symbols returns the symbols declared in the packages of the module tag
+func Symbols(module, tag string, packages []*v1.Package) []*Symbol {
+	var symbols []*Symbol
+
+	for _, pkg := range packages {
+		for _, file := range pkg.GetProtoFiles() {
+			add := func(kind, path string) {
+				symbols = append(symbols, &Symbol{
+					Module:  module,
+					Tag:     tag,
+					Package: pkg.GetName(),
+					File:    file.GetFilename(),
+					Kind:    kind,
+					Path:    path,
+				})
+			}
+
+			for _, service := range file.GetServices() {
+				servicePath := join(pkg.GetName(), service.GetName())
+				add(metadata.ElementService, servicePath)
+				for _, method := range service.GetMethods() {
+					add(metadata.ElementRPC, join(servicePath, method.GetName()))
+				}
+			}
+
+			for _, message := range file.GetMessages() {
+				addMessage(add, pkg.GetName(), message)
+			}
+		}
+	}
+
+	return symbols
+}
+
+func addMessage(add func(kind, path string), scope string, message *v1.Message) {
+	messagePath := join(scope, message.GetName())
+	add(metadata.ElementMessage, messagePath)
+
+	for _, field := range message.GetFields() {
+		if field.GetOneof() {
+			for _, name := range field.GetOneofNames() {
+				add(metadata.ElementField, join(messagePath, name))
+			}
+			continue
+		}
+		add(metadata.ElementField, join(messagePath, field.GetName()))
+	}
+
+	for _, enum := range message.GetNestedEnums() {
+		add(metadata.ElementEnum, join(messagePath, enum.GetName()))
+	}
+
+	for _, nested := range message.GetNestedMessages() {
+		addMessage(add, messagePath, nested)
+	}
+}
+
+// Query matches the symbols
+type Query struct {
+	text   string
+	regexp *regexp.Regexp
+	kind   string
+}
+
+// NewQuery creates a query. A plain query matches the symbol names containing the text
+// ignoring case. A regex query matches the full symbol paths, e.g. `^acme\..*\.Money$`.
+// If the kind is not empty, only the symbols of the kind are matched
+func NewQuery(text string, regex bool, kind string) (*Query, error) {
+	if kind != "" && !isKind(kind) {
+		return nil, fmt.Errorf("unknown kind %s, expected one of: %s", kind, strings.Join(Kinds, ", "))
+	}
+
+	query := &Query{text: strings.ToLower(text), kind: kind}
+	if regex {
+		compiled, err := regexp.Compile(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %s: %w", text, err)
+		}
+		query.regexp = compiled
+	}
+
+	return query, nil
+}
+
+// Match reports whether the symbol matches the query
+func (q *Query) Match(symbol *Symbol) bool {
+	if q.kind != "" && symbol.Kind != q.kind {
+		return false
+	}
+
+	if q.regexp != nil {
+		return q.regexp.MatchString(symbol.Path)
+	}
+
+	return strings.Contains(strings.ToLower(symbol.Name()), q.text)
+}
+
+// Find returns the symbols of the index matching the query
+func (q *Query) Find(index *Index) []*Symbol {
+	var found []*Symbol
+	for _, module := range index.Modules {
+		for _, symbol := range module.Symbols {
+			if q.Match(symbol) {
+				found = append(found, symbol)
+			}
+		}
+	}
+	return found
+}
+
+func isKind(kind string) bool {
+	for _, k := range Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func join(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
@@ -0,0 +1,174 @@
+package search
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"google.golang.org/grpc"
+)
+
+type fakeRegistryClient struct {
+	v1.RegistryClient
+	modules []*v1.Module
+}
+
+func (c *fakeRegistryClient) ListModules(_ context.Context, _ *v1.ListModulesRequest, _ ...grpc.CallOption) (*v1.ListModulesResponse, error) {
+	return &v1.ListModulesResponse{Modules: c.modules}, nil
+}
+
+func (c *fakeRegistryClient) GetModule(_ context.Context, in *v1.GetModuleRequest, _ ...grpc.CallOption) (*v1.Module, error) {
+	for _, module := range c.modules {
+		if module.Name == in.Name {
+			return module, nil
+		}
+	}
+	return nil, context.Canceled
+}
+
+type fakeMetadataClient struct {
+	v1.MetadataServiceClient
+	calls []string
+}
+
+func (c *fakeMetadataClient) GetMetadata(_ context.Context, in *v1.GetMetadataRequest, _ ...grpc.CallOption) (*v1.GetMetadataResponse, error) {
+	c.calls = append(c.calls, in.Name+"@"+in.Tag)
+	return &v1.GetMetadataResponse{
+		Packages: []*v1.Package{
+			{
+				Name: "acme.v1",
+				ProtoFiles: []*v1.ParsedProtoFile{
+					{
+						Filename: "acme/v1/payments.proto",
+						Services: []*v1.Service{{Name: "Payments", Methods: []*v1.Method{{Name: "Pay"}}}},
+						Messages: []*v1.Message{
+							{
+								Name: "PayRequest",
+								Fields: []*v1.Field{
+									{Name: "amount", MessageType: "Money"},
+									{Name: "method", Oneof: true, OneofNames: []string{"card"}},
+								},
+								NestedEnums:    []*v1.Enum{{Name: "Status"}},
+								NestedMessages: []*v1.Message{{Name: "Money"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func TestQuery(t *testing.T) {
+	client := &fakeMetadataClient{}
+	response, _ := client.GetMetadata(context.Background(), &v1.GetMetadataRequest{})
+	index := &Index{
+		Modules: []*IndexedModule{
+			{Name: "acme/payments", Tag: "v1.0.0", Symbols: Symbols("acme/payments", "v1.0.0", response.Packages)},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		text  string
+		regex bool
+		kind  string
+		want  []string
+	}{
+		{
+			name: "name contains text ignoring case",
+			text: "money",
+			want: []string{"message acme.v1.PayRequest.Money"},
+		},
+		{
+			name: "name only",
+			text: "acme",
+		},
+		{
+			name: "oneof variants",
+			text: "card",
+			want: []string{"field acme.v1.PayRequest.card"},
+		},
+		{
+			name: "kind",
+			text: "pay",
+			kind: "rpc",
+			want: []string{"rpc acme.v1.Payments.Pay"},
+		},
+		{
+			name:  "regex on path",
+			text:  `^acme\.v1\.Pay[a-zA-Z]*$`,
+			regex: true,
+			want:  []string{"service acme.v1.Payments", "message acme.v1.PayRequest"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := NewQuery(tt.text, tt.regex, tt.kind)
+			if err != nil {
+				t.Fatalf("NewQuery() error = %v", err)
+			}
+
+			var got []string
+			for _, symbol := range query.Find(index) {
+				got = append(got, symbol.Kind+" "+symbol.Path)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Find() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewQuery("x", false, "package"); err == nil {
+		t.Errorf("NewQuery() expected error for unknown kind")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	registryClient := &fakeRegistryClient{
+		modules: []*v1.Module{
+			{Name: "acme/payments", Tags: []string{"v1.1.0", "v1.0.0"}},
+			{Name: "acme/orders", Tags: []string{"v2.0.0"}},
+			{Name: "acme/empty"},
+		},
+	}
+	metadataClient := &fakeMetadataClient{}
+
+	previous := &Index{
+		Modules: []*IndexedModule{
+			{Name: "acme/payments", Tag: "v1.1.0"},
+			{Name: "acme/orders", Tag: "v1.0.0"},
+		},
+	}
+
+	index, err := Build(context.Background(), registryClient, metadataClient, previous)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	// payments is up to date in the previous index, orders has a new tag
+	if want := []string{"acme/orders@v2.0.0"}; !reflect.DeepEqual(metadataClient.calls, want) {
+		t.Errorf("Build() got metadata calls = %v, want %v", metadataClient.calls, want)
+	}
+
+	if len(index.Modules) != 2 || index.Modules[1].Tag != "v2.0.0" || len(index.Modules[1].Symbols) == 0 {
+		t.Errorf("Build() got modules = %v", index.Modules)
+	}
+
+	path := filepath.Join(t.TempDir(), "pbuf", "search.json")
+	if err := index.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(loaded.Modules, index.Modules) || !loaded.UpdatedAt.Equal(index.UpdatedAt) {
+		t.Errorf("Load() got = %v, want %v", loaded, index)
+	}
+}
@@ -0,0 +1,124 @@
+package validate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/bufbuild/protocompile/reporter"
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/schema"
+)
+
+// wellKnownPrefix is the prefix of the imports shipped with protoc
+const wellKnownPrefix = "google/protobuf/"
+
+// Problem is an unresolved import or a compilation error of a file
+type Problem struct {
+	Filename string
+	Line     int
+	Import   string
+	Message  string
+}
+
+func (p *Problem) String() string {
+	return fmt.Sprintf("%s:%d: %s", p.Filename, p.Line, p.Message)
+}
+
+// ImportSet is a named set of files the imports can be resolved with.
+// The file names are the paths the files are imported with
+type ImportSet struct {
+	Name  string
+	Files []*v1.ProtoFile
+}
+
+// Resolve returns the name of the first set that contains the imported file
+func Resolve(imported string, sets []ImportSet) (string, bool) {
+	for _, set := range sets {
+		for _, file := range set.Files {
+			if file.Filename == imported {
+				return set.Name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// UnresolvedImports returns the imports that cannot be resolved
+// with the files themselves, the well-known types and the import sets
+func UnresolvedImports(files []*schema.File, sets []ImportSet) []*Problem {
+	var exported []*v1.ProtoFile
+	for _, file := range files {
+		exported = append(exported, &v1.ProtoFile{Filename: file.Name})
+	}
+
+	sets = append([]ImportSet{{Name: "export", Files: exported}}, sets...)
+
+	var problems []*Problem
+	for _, file := range files {
+		for _, imported := range file.Proto.ProtoBody.Imports {
+			location := strings.Trim(imported.Location, `"'`)
+			if strings.HasPrefix(location, wellKnownPrefix) {
+				continue
+			}
+
+			if _, ok := Resolve(location, sets); ok {
+				continue
+			}
+
+			problems = append(problems, &Problem{
+				Filename: file.Name,
+				Line:     imported.Meta.Pos.Line,
+				Import:   location,
+				Message:  fmt.Sprintf("import %q is neither exported nor provided by a registry dependency", location),
+			})
+		}
+	}
+
+	return problems
+}
+
+// Compile compiles the files against the import sets and the well-known types
+// and returns the compilation errors, e.g. unresolved types
+func Compile(ctx context.Context, protoFiles []*v1.ProtoFile, sets []ImportSet) ([]*Problem, error) {
+	sources := map[string]string{}
+	for _, set := range sets {
+		for _, file := range set.Files {
+			if _, ok := sources[file.Filename]; !ok {
+				sources[file.Filename] = file.Content
+			}
+		}
+	}
+
+	var filenames []string
+	for _, file := range protoFiles {
+		sources[file.Filename] = file.Content
+		filenames = append(filenames, file.Filename)
+	}
+
+	var problems []*Problem
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(sources),
+		}),
+		Reporter: reporter.NewReporter(func(err reporter.ErrorWithPos) error {
+			position := err.GetPosition()
+			problems = append(problems, &Problem{
+				Filename: position.Filename,
+				Line:     position.Line,
+				Message:  err.Unwrap().Error(),
+			})
+			// keep going to report all errors at once
+			return nil
+		}, nil),
+	}
+
+	_, err := compiler.Compile(ctx, filenames...)
+	if err != nil && !errors.Is(err, reporter.ErrInvalidSource) {
+		return nil, err
+	}
+
+	return problems, nil
+}
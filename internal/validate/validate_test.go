@@ -0,0 +1,150 @@
+package validate
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	v1 "github.com/pbufio/pbuf-cli/gen/pbuf-registry/v1"
+	"github.com/pbufio/pbuf-cli/internal/schema"
+)
+
+const moneyProtoFile = `syntax = "proto3";
+package acme.v1;
+
+message Money {
+  string currency = 1;
+}
+`
+
+func TestUnresolvedImports(t *testing.T) {
+	tests := []struct {
+		name    string
+		imports string
+		sets    []ImportSet
+		want    []string
+	}{
+		{
+			name:    "exported file",
+			imports: `import "api/acme/v1/money.proto";`,
+		},
+		{
+			name:    "well-known types",
+			imports: `import "google/protobuf/timestamp.proto";`,
+		},
+		{
+			name:    "registry dependency",
+			imports: `import "acme/common/v1/id.proto";`,
+			sets: []ImportSet{
+				{Name: "acme/common", Files: []*v1.ProtoFile{{Filename: "acme/common/v1/id.proto"}}},
+			},
+		},
+		{
+			name: "unknown imports",
+			imports: `import "google/api/annotations.proto";
+import "acme/v1/money.proto";
+import "v1/money.proto";
+import "cme/v1/money.proto";`,
+			sets: []ImportSet{
+				{Name: "acme/common", Files: []*v1.ProtoFile{{Filename: "common/acme/common/v1/id.proto"}}},
+			},
+			want: []string{
+				`api/acme/v1/payments.proto:3: import "google/api/annotations.proto" is neither exported nor provided by a registry dependency`,
+				`api/acme/v1/payments.proto:4: import "acme/v1/money.proto" is neither exported nor provided by a registry dependency`,
+				`api/acme/v1/payments.proto:5: import "v1/money.proto" is neither exported nor provided by a registry dependency`,
+				`api/acme/v1/payments.proto:6: import "cme/v1/money.proto" is neither exported nor provided by a registry dependency`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := schema.ParseAll([]*v1.ProtoFile{
+				{Filename: "api/acme/v1/money.proto", Content: moneyProtoFile},
+				{Filename: "api/acme/v1/payments.proto", Content: "syntax = \"proto3\";\npackage acme.v1;\n" + tt.imports + "\n"},
+			})
+			if err != nil {
+				t.Fatalf("ParseAll() error = %v", err)
+			}
+
+			var got []string
+			for _, problem := range UnresolvedImports(files, tt.sets) {
+				got = append(got, problem.String())
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnresolvedImports() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	dependency := ImportSet{
+		Name: "acme/common",
+		Files: []*v1.ProtoFile{
+			{Filename: "acme/common/v1/id.proto", Content: "syntax = \"proto3\";\npackage acme.common.v1;\n\nmessage ID {\n  string value = 1;\n}\n"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		sets    []ImportSet
+		want    []string
+	}{
+		{
+			name: "resolved types",
+			content: `import "api/acme/v1/money.proto";
+import "acme/common/v1/id.proto";
+import "google/protobuf/timestamp.proto";
+
+message Payment {
+  acme.common.v1.ID id = 1;
+  Money amount = 2;
+  google.protobuf.Timestamp created_at = 3;
+}`,
+			sets: []ImportSet{dependency},
+		},
+		{
+			name: "unresolved type",
+			content: `import "api/acme/v1/money.proto";
+
+message Payment {
+  Money amount = 1;
+  Currency currency = 2;
+}`,
+			want: []string{
+				`api/acme/v1/payments.proto:7: field acme.v1.Payment.currency: unknown type Currency`,
+			},
+		},
+		{
+			name: "type of a missing dependency",
+			content: `message Payment {
+  acme.common.v1.ID id = 1;
+}`,
+			want: []string{
+				`api/acme/v1/payments.proto:4: field acme.v1.Payment.id: unknown type acme.common.v1.ID`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems, err := Compile(context.Background(), []*v1.ProtoFile{
+				{Filename: "api/acme/v1/money.proto", Content: moneyProtoFile},
+				{Filename: "api/acme/v1/payments.proto", Content: "syntax = \"proto3\";\npackage acme.v1;\n" + tt.content + "\n"},
+			}, tt.sets)
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+
+			var got []string
+			for _, problem := range problems {
+				got = append(got, problem.String())
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compile() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package version
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// Bump parts
+const (
+	BumpPatch = "patch"
+	BumpMinor = "minor"
+	BumpMajor = "major"
+	// BumpAuto selects the part with Suggest
+	BumpAuto = "auto"
+)
+
+var bumpOrder = map[string]int{
+	BumpPatch: 0,
+	BumpMinor: 1,
+	BumpMajor: 2,
+}
+
+// ParseBump validates the bump part
+func ParseBump(part string) (string, error) {
+	switch part {
+	case BumpPatch, BumpMinor, BumpMajor, BumpAuto:
+		return part, nil
+	default:
+		return "", fmt.Errorf("unknown bump %q. use patch, minor, major or auto", part)
+	}
+}
+
+// Latest returns the highest semver release tag.
+// Tags that are not semver or are pre-releases are skipped.
+// The result is empty if there are no such tags
+func Latest(tags []string) string {
+	latest := ""
+	for _, tag := range tags {
+		v := canonical(tag)
+		if v == "" || semver.Prerelease(v) != "" {
+			continue
+		}
+		if latest == "" || semver.Compare(v, canonical(latest)) > 0 {
+			latest = tag
+		}
+	}
+	return latest
+}
+
+// Next returns the latest tag bumped by the part.
+// The tags without `v` prefix produce tags without it.
+// If there is no latest tag, v0.0.0 is bumped
+func Next(latest, part string) (string, error) {
+	prefix := "v"
+	if latest == "" {
+		latest = "v0.0.0"
+	} else if !strings.HasPrefix(latest, "v") {
+		prefix = ""
+	}
+
+	v := canonical(latest)
+	if v == "" {
+		return "", fmt.Errorf("tag %s is not a semantic version", latest)
+	}
+
+	var major, minor, patch int
+	_, err := fmt.Sscanf(strings.TrimPrefix(semver.Canonical(v), "v"), "%d.%d.%d", &major, &minor, &patch)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse tag %s: %w", latest, err)
+	}
+
+	switch part {
+	case BumpMajor:
+		major, minor, patch = major+1, 0, 0
+	case BumpMinor:
+		minor, patch = minor+1, 0
+	case BumpPatch:
+		patch++
+	default:
+		return "", fmt.Errorf("unknown bump %q", part)
+	}
+
+	return fmt.Sprintf("%s%d.%d.%d", prefix, major, minor, patch), nil
+}
+
+// Suggest returns the bump part required after the latest tag.
+// Breaking changes require a major bump, or a minor bump before v1.0.0
+func Suggest(latest string, breaking bool) string {
+	if !breaking {
+		return BumpPatch
+	}
+
+	v := canonical(latest)
+	if v == "" || semver.Major(v) == "v0" {
+		return BumpMinor
+	}
+
+	return BumpMajor
+}
+
+// Less reports whether the bump part a is smaller than b
+func Less(a, b string) bool {
+	return bumpOrder[a] < bumpOrder[b]
+}
+
+// FromGit derives the tag with `git describe --tags --exact-match` in the dir.
+// The current commit must be tagged with a semantic version
+func FromGit(ctx context.Context, dir string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	command := exec.CommandContext(ctx, "git", "describe", "--tags", "--exact-match")
+	command.Dir = dir
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+
+	err := command.Run()
+	if err != nil {
+		return "", fmt.Errorf("the current commit is not tagged, git describe failed: %w: %s",
+			err, strings.TrimSpace(stderr.String()))
+	}
+
+	tag := strings.TrimSpace(stdout.String())
+	if canonical(tag) == "" {
+		return "", fmt.Errorf("git tag %s is not a semantic version", tag)
+	}
+
+	return tag, nil
+}
+
+// canonical returns the tag with `v` prefix if it is a valid semver, or empty string
+func canonical(tag string) string {
+	v := tag
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	if !semver.IsValid(v) {
+		return ""
+	}
+	return v
+}
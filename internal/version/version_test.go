@@ -0,0 +1,124 @@
+package version
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+)
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []string
+		part    string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "no tags",
+			part: BumpPatch,
+			want: "v0.0.1",
+		},
+		{
+			name: "patch",
+			tags: []string{"v1.2.3", "v1.10.0", "v1.9.9"},
+			part: BumpPatch,
+			want: "v1.10.1",
+		},
+		{
+			name: "minor",
+			tags: []string{"v1.2.3"},
+			part: BumpMinor,
+			want: "v1.3.0",
+		},
+		{
+			name: "major",
+			tags: []string{"v1.2.3"},
+			part: BumpMajor,
+			want: "v2.0.0",
+		},
+		{
+			name: "pre-releases and other tags are skipped",
+			tags: []string{"v2.0.0-rc.1", "latest", "v1.2.3"},
+			part: BumpPatch,
+			want: "v1.2.4",
+		},
+		{
+			name: "tags without prefix",
+			tags: []string{"0.4.1"},
+			part: BumpMinor,
+			want: "0.5.0",
+		},
+		{
+			name:    "unknown part",
+			tags:    []string{"v1.2.3"},
+			part:    "build",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Next(Latest(tt.tags), tt.part)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Next() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Next() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		name     string
+		latest   string
+		breaking bool
+		want     string
+	}{
+		{name: "no breaking changes", latest: "v1.2.3", want: BumpPatch},
+		{name: "breaking changes", latest: "v1.2.3", breaking: true, want: BumpMajor},
+		{name: "breaking changes before v1", latest: "v0.2.3", breaking: true, want: BumpMinor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Suggest(tt.latest, tt.breaking); got != tt.want {
+				t.Errorf("Suggest() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromGit(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	git := func(args ...string) {
+		command := exec.Command("git", args...)
+		command.Dir = dir
+		if output, err := command.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, output)
+		}
+	}
+
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "first")
+	git("tag", "v1.2.3")
+
+	got, err := FromGit(context.Background(), dir)
+	if err != nil || got != "v1.2.3" {
+		t.Errorf("FromGit() got = %v, %v, want v1.2.3", got, err)
+	}
+
+	// `git describe --tags` returns v1.2.3-1-g<sha> after the tag, a valid prerelease
+	git("commit", "-q", "--allow-empty", "-m", "second")
+
+	got, err = FromGit(context.Background(), dir)
+	if err == nil {
+		t.Errorf("FromGit() got = %v, want error for an untagged commit", got)
+	}
+}
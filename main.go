@@ -17,7 +17,8 @@ const (
 func main() {
 	log.SetFlags(noFlags)
 	log.SetPrefix(noPrefix)
-	log.SetOutput(os.Stdout)
+	// stdout is reserved for the command output
+	log.SetOutput(os.Stderr)
 
 	err := cmd.NewRootCmd().Execute()
 	if err != nil {
@@ -29,7 +30,7 @@ func init() {
 	log.SetOutput(io.Discard)
 	_, err := maxprocs.Set(maxprocs.Logger(log.Printf))
 	if err != nil {
-		log.SetOutput(os.Stdout)
+		log.SetOutput(os.Stderr)
 		log.Printf("failed to set maxprocs: %v", err)
 	}
 }